@@ -0,0 +1,43 @@
+// This file contains the config file, for settings that don't fit in CLI
+// args or env vars.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of the config file
+type Config struct {
+	// Synonyms are groups of words that match each other, like "delete ~ rm ~ remove"
+	Synonyms []string `yaml:"synonyms"`
+	// Keys configures the keybindings (see NewKeyMap)
+	Keys KeysConfig `yaml:"keys"`
+	// DangerousPatterns are parts of commands that need confirmation (see
+	// DefaultDangerousPatterns)
+	DangerousPatterns []string `yaml:"dangerous_patterns"`
+}
+
+// LoadConfigFile reads the config file at `path`. A missing file is an empty
+// config.
+func LoadConfigFile(path string) (Config, error) {
+	config := Config{}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if _, err := NewKeyMap(config.Keys); err != nil {
+		return config, fmt.Errorf("invalid keys in config file: %w", err)
+	}
+	return config, nil
+}
@@ -0,0 +1,64 @@
+package main_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__LoadConfigFile(t *testing.T) {
+	t.Run("missing file is empty config", func(t *testing.T) {
+		config, err := sazed.LoadConfigFile(path.Join(t.TempDir(), "config.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.Config{}, config)
+	})
+	t.Run("loads synonyms", func(t *testing.T) {
+		configFile := path.Join(t.TempDir(), "config.yaml")
+		_ = os.WriteFile(configFile, []byte("synonyms:\n  - delete ~ rm ~ remove\n  - list ~ ls\n"), 0644)
+
+		config, err := sazed.LoadConfigFile(configFile)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"delete ~ rm ~ remove", "list ~ ls"}, config.Synonyms)
+	})
+	t.Run("loads keys", func(t *testing.T) {
+		configFile := path.Join(t.TempDir(), "config.yaml")
+		_ = os.WriteFile(configFile, []byte("keys:\n  style: vim\n  bindings:\n    quit: [esc]\n"), 0644)
+
+		config, err := sazed.LoadConfigFile(configFile)
+
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.KeysConfig{
+			Style:    sazed.KeyStyleVim,
+			Bindings: map[sazed.KeyAction][]string{sazed.KeyActionQuit: {"esc"}},
+		}, config.Keys)
+	})
+	t.Run("loads dangerous patterns", func(t *testing.T) {
+		configFile := path.Join(t.TempDir(), "config.yaml")
+		_ = os.WriteFile(configFile, []byte("dangerous_patterns: [reboot, shutdown]\n"), 0644)
+
+		config, err := sazed.LoadConfigFile(configFile)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"reboot", "shutdown"}, config.DangerousPatterns)
+	})
+	t.Run("errors on invalid keys", func(t *testing.T) {
+		configFile := path.Join(t.TempDir(), "config.yaml")
+		_ = os.WriteFile(configFile, []byte("keys:\n  style: foo\n"), 0644)
+
+		_, err := sazed.LoadConfigFile(configFile)
+
+		assert.ErrorContains(t, err, "invalid keys in config file: invalid key style: foo")
+	})
+	t.Run("errors on invalid yaml", func(t *testing.T) {
+		configFile := path.Join(t.TempDir(), "config.yaml")
+		_ = os.WriteFile(configFile, []byte("synonyms: {"), 0644)
+
+		_, err := sazed.LoadConfigFile(configFile)
+
+		assert.ErrorContains(t, err, "failed to parse config file")
+	})
+}
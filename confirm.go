@@ -0,0 +1,109 @@
+// This file contains the confirmation page, shown before outputting
+// dangerous commands.
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultDangerousPatterns are used if the config file has none
+var DefaultDangerousPatterns = []string{"rm -rf", "DROP TABLE", "--force", "kubectl delete"}
+
+// ConfirmAnswer is what the user types to confirm a dangerous command
+const ConfirmAnswer = "yes"
+
+// DangerStyle is the style for commands that need confirmation
+var DangerStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196"))
+
+// DangerousPatterns returns the configured dangerous patterns, or the
+// default ones if not configured
+func DangerousPatterns(config Config) []string {
+	if config.DangerousPatterns == nil {
+		return DefaultDangerousPatterns
+	}
+	return config.DangerousPatterns
+}
+
+// MatchDangerousPatterns returns the `patterns` found in `command`, ignoring
+// case
+func MatchDangerousPatterns(command string, patterns []string) []string {
+	matched := []string{}
+	for _, pattern := range patterns {
+		if pattern != "" && strings.Contains(strings.ToLower(command), strings.ToLower(pattern)) {
+			matched = append(matched, pattern)
+		}
+	}
+	return matched
+}
+
+// NeedsConfirm returns whether `command` must be confirmed before it's output,
+// because the selected memory asks for it or it matches a dangerous pattern
+func NeedsConfirm(m Model, command string) bool {
+	return m.SelectedMemory.Confirm || len(MatchDangerousPatterns(command, DangerousPatterns(m.AppOpts.Config))) > 0
+}
+
+// OutputExecution quits with `execution` as output, asking for confirmation
+// first if needed
+func OutputExecution(m Model, execution Execution) (Model, tea.Cmd) {
+	if NeedsConfirm(m, execution.ShellCommand()) {
+		return StartConfirm(m, execution)
+	}
+	return m, QuitWithExecution(execution)
+}
+
+// StartConfirm shows the confirmation page for `execution`
+func StartConfirm(m Model, execution Execution) (Model, tea.Cmd) {
+	m.ConfirmExecution = execution
+	m.ConfirmSteps = nil
+	m.ConfirmPreviousPage = m.CurrentPage
+	m.ConfirmInput = textinput.New()
+	m.ConfirmInput.Cursor.SetMode(cursor.CursorStatic)
+	m.ConfirmInput.Prompt = "Type " + ConfirmAnswer + " to confirm: "
+	m.CurrentPage = PageConfirm
+	return m, m.ConfirmInput.Focus()
+}
+
+// SubmitConfirm quits with the command, or starts running the steps, if the
+// user typed the answer
+func SubmitConfirm(m Model) (Model, tea.Cmd) {
+	if strings.TrimSpace(m.ConfirmInput.Value()) != ConfirmAnswer {
+		return m, nil
+	}
+	if m.ConfirmSteps != nil {
+		return StartSteps(m, m.ConfirmSteps)
+	}
+	return m, QuitWithExecution(m.ConfirmExecution)
+}
+
+// BackFromConfirm returns to the page that asked for confirmation
+func BackFromConfirm(m Model) Model {
+	m.ConfirmExecution = Execution{}
+	m.ConfirmSteps = nil
+	if m.ConfirmPreviousPage == PageSelect {
+		return BackToSelect(m)
+	}
+	m.CurrentPage = m.ConfirmPreviousPage
+	return m
+}
+
+func ViewCommandConfirm(m Model) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("This command needs confirmation:\n")
+	command := m.ConfirmExecution.ShellCommand()
+	stringBuilder.WriteString(DangerStyle.Render(command))
+	stringBuilder.WriteString("\n")
+	matched := MatchDangerousPatterns(command, DangerousPatterns(m.AppOpts.Config))
+	if len(matched) > 0 {
+		stringBuilder.WriteString("Dangerous: ")
+		stringBuilder.WriteString(strings.Join(matched, ", "))
+		stringBuilder.WriteString("\n")
+	}
+	stringBuilder.WriteString(m.ConfirmInput.View())
+	stringBuilder.WriteString("\n")
+	return stringBuilder.String()
+}
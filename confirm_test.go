@@ -0,0 +1,87 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestMatchDangerousPatterns(t *testing.T) {
+	patterns := sazed.DefaultDangerousPatterns
+	assert.Equal(t, []string{"rm -rf"}, sazed.MatchDangerousPatterns("rm -rf /tmp/foo", patterns))
+	assert.Equal(t, []string{"DROP TABLE", "--force"}, sazed.MatchDangerousPatterns("psql -c 'drop table x' --force", patterns))
+	assert.Equal(t, []string{}, sazed.MatchDangerousPatterns("ls -la", patterns))
+	assert.Equal(t, []string{}, sazed.MatchDangerousPatterns("ls -la", []string{""}))
+}
+
+func TestDangerousPatterns(t *testing.T) {
+	assert.Equal(t, sazed.DefaultDangerousPatterns, sazed.DangerousPatterns(sazed.Config{}))
+	assert.Equal(t, []string{}, sazed.DangerousPatterns(sazed.Config{DangerousPatterns: []string{}}))
+	assert.Equal(t, []string{"reboot"}, sazed.DangerousPatterns(sazed.Config{DangerousPatterns: []string{"reboot"}}))
+}
+
+func TestConfirm(t *testing.T) {
+	t.Run("asks before outputting dangerous commands", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{sazed.Memory{Command: "rm -rf /tmp/foo"}}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageConfirm, m.CurrentPage)
+		assert.Equal(t, "", sazed.QuitOutput)
+
+		view := sazed.ViewCommandConfirm(m)
+		assert.Contains(t, view, sazed.DangerStyle.Render("rm -rf /tmp/foo"))
+		assert.Contains(t, view, "Dangerous: rm -rf")
+
+		m = typeText(m, "y")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, "", sazed.QuitOutput)
+
+		m = typeText(m, "es")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, "rm -rf /tmp/foo", sazed.QuitOutput)
+	})
+	t.Run("asks for memories with confirm", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{sazed.Memory{Command: "shutdown {{when}}", Confirm: true}}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "now")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageConfirm, m.CurrentPage)
+		assert.Contains(t, sazed.ViewCommandConfirm(m), "shutdown now")
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, sazed.PageEdit, m.CurrentPage)
+		assert.Equal(t, "", sazed.QuitOutput)
+	})
+	t.Run("checks the rendered command", func(t *testing.T) {
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{sazed.Memory{Command: "git push {{flags}}"}}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "--force")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageConfirm, m.CurrentPage)
+	})
+	t.Run("goes back to the selection", func(t *testing.T) {
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{sazed.Memory{Command: "kubectl delete pod foo"}}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, sazed.PageSelect, m.CurrentPage)
+		assert.False(t, strings.Contains(sazed.ViewCommandSelection(m), "Type yes"))
+	})
+	t.Run("uses configured patterns", func(t *testing.T) {
+		defer cleanup()
+		m := newTestModel(func(opts *sazed.AppOptions) {
+			opts.Config = sazed.Config{DangerousPatterns: []string{"reboot"}}
+		})
+		m = update(m, sazed.LoadedMemories([]sazed.Memory{{Command: "rm -rf /tmp/foo"}}))
+
+		update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, "rm -rf /tmp/foo", sazed.QuitOutput)
+	})
+}
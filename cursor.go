@@ -1,15 +1,104 @@
 package main
 
+// SelectionHeaderLines is how many lines the selection page uses above the matches
+const SelectionHeaderLines = 3
+
+// SelectionFooterLines is how many lines the selection page uses below the
+// matches, for the help footer
+const SelectionFooterLines = 1
+
+// SelectionLinesPerMatch is how many lines each match uses in the selection page
+const SelectionLinesPerMatch = 2
+
 func IncreaseMatchCursor(m Model) Model {
-	if m.MatchCursor < len(m.Memories)-1 {
-		m.MatchCursor++
-	}
-	return m
+	return MoveMatchCursor(m, 1)
 }
 
 func DecreaseMatchCursor(m Model) Model {
-	if m.MatchCursor > 0 {
-		m.MatchCursor--
+	return MoveMatchCursor(m, -1)
+}
+
+// MoveMatchCursor moves the cursor by `delta` rows, stopping at the first
+// and last matches.
+func MoveMatchCursor(m Model, delta int) Model {
+	m.MatchCursor = max(min(m.MatchCursor+delta, len(m.Matches)-1), 0)
+	return ScrollToCursor(m)
+}
+
+// PageDownMatchCursor moves the cursor one page of matches down
+func PageDownMatchCursor(m Model) Model {
+	return MoveMatchCursor(m, VisibleMatchesCount(m))
+}
+
+// PageUpMatchCursor moves the cursor one page of matches up
+func PageUpMatchCursor(m Model) Model {
+	return MoveMatchCursor(m, -VisibleMatchesCount(m))
+}
+
+// HomeMatchCursor moves the cursor to the first match
+func HomeMatchCursor(m Model) Model {
+	return MoveMatchCursor(m, -len(m.Matches))
+}
+
+// EndMatchCursor moves the cursor to the last match
+func EndMatchCursor(m Model) Model {
+	return MoveMatchCursor(m, len(m.Matches))
+}
+
+// VisibleMatchesCount returns how many matches fit in the terminal, leaving
+// room for the preview at the bottom and the help footer. If the terminal
+// size is unknown, all matches are visible.
+func VisibleMatchesCount(m Model) int {
+	if m.Height <= 0 {
+		return max(len(m.Matches), 1)
+	}
+	return max((m.Height-SelectionHeaderLines-SelectionFooterLines-PreviewBottomLines(m))/SelectionLinesPerMatch, 1)
+}
+
+// ScrollToCursor scrolls the matches so that the cursor is visible
+func ScrollToCursor(m Model) Model {
+	visible := VisibleMatchesCount(m)
+	if m.MatchCursor < m.MatchOffset {
+		m.MatchOffset = m.MatchCursor
+	}
+	if m.MatchCursor >= m.MatchOffset+visible {
+		m.MatchOffset = m.MatchCursor - visible + 1
 	}
+	m.MatchOffset = max(min(m.MatchOffset, len(m.Matches)-visible), 0)
 	return m
 }
+
+// CursorMatch returns the match under the cursor, if any
+func CursorMatch(m Model) (Match, bool) {
+	if m.MatchCursor < 0 || m.MatchCursor >= len(m.Matches) {
+		return Match{}, false
+	}
+	return m.Matches[m.MatchCursor], true
+}
+
+// SetMatches replaces the matches, keeping the cursor on the same memory if
+// it's still matched. Otherwise the cursor is clamped to a valid row.
+func SetMatches(m Model, matches []Match) Model {
+	selected, hasSelected := CursorMatch(m)
+	m.Matches = matches
+	m.MatchCursor = max(min(m.MatchCursor, len(m.Matches)-1), 0)
+	if hasSelected {
+		for i, match := range m.Matches {
+			if match.Index == selected.Index {
+				m.MatchCursor = i
+				break
+			}
+		}
+	}
+	return ScrollToCursor(m)
+}
+
+// Resize handles a change in the terminal size
+func Resize(m Model, width int, height int) Model {
+	m.Width = width
+	m.Height = height
+	if m.CurrentPage == PageReview {
+		m.ReviewTextArea.SetWidth(width)
+	}
+	return ScrollToCursor(m)
+}
@@ -1,6 +1,7 @@
 package main_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,8 +15,8 @@ func TestIncreaseMatchCursor(t *testing.T) {
 		sazed.Memory{Command: "not foo", Description: "not bar"},
 	}
 
-	model := sazed.InitialModel(sazed.AppOptions{})
-	model.Memories = memories
+	model := newTestModel()
+	model = sazed.LoadMemories(model, memories)
 	model = sazed.IncreaseMatchCursor(model)
 	model = sazed.IncreaseMatchCursor(model)
 
@@ -36,3 +37,96 @@ func TestIncreaseMatchCursor(t *testing.T) {
 
 	assert.Equal(t, model.MatchCursor, 0) // Can't go below 1
 }
+
+func TestMatchCursorFollowsMatches(t *testing.T) {
+	memories := []sazed.Memory{
+		{Command: "cmd1", Description: "Memory 1"},
+		{Command: "foo", Description: "Bar"},
+		{Command: "not foo", Description: "not bar"},
+	}
+	model := newTestModel()
+	model = sazed.LoadMemories(model, memories)
+	model.SearchTextInput.SetValue("'bar")
+	model = sazed.UpdateMatchesNow(model)
+
+	model = sazed.IncreaseMatchCursor(model)
+	model = sazed.IncreaseMatchCursor(model)
+
+	assert.Equal(t, 1, model.MatchCursor) // Only 2 matches
+}
+
+func manyMatches(n int) []sazed.Match {
+	matches := make([]sazed.Match, n)
+	for i := range matches {
+		matches[i] = sazed.Match{Memory: sazed.Memory{Command: fmt.Sprintf("cmd%d", i)}, Index: i}
+	}
+	return matches
+}
+
+func TestScrolling(t *testing.T) {
+	model := newTestModel()
+	model.Matches = manyMatches(20)
+	// 3 header lines + 5 matches with 2 lines each + 1 footer line
+	model = sazed.Resize(model, 80, 14)
+	assert.Equal(t, 5, sazed.VisibleMatchesCount(model))
+
+	t.Run("scrolls down when cursor leaves the screen", func(t *testing.T) {
+		m := model
+		for i := 0; i < 6; i++ {
+			m = sazed.IncreaseMatchCursor(m)
+		}
+		assert.Equal(t, 6, m.MatchCursor)
+		assert.Equal(t, 2, m.MatchOffset)
+		m = sazed.DecreaseMatchCursor(m)
+		assert.Equal(t, 2, m.MatchOffset)
+	})
+	t.Run("page down and up", func(t *testing.T) {
+		m := sazed.PageDownMatchCursor(model)
+		assert.Equal(t, 5, m.MatchCursor)
+		assert.Equal(t, 1, m.MatchOffset)
+		m = sazed.PageUpMatchCursor(sazed.PageDownMatchCursor(m))
+		assert.Equal(t, 5, m.MatchCursor)
+		assert.Equal(t, 5, m.MatchOffset)
+	})
+	t.Run("home and end", func(t *testing.T) {
+		m := sazed.EndMatchCursor(model)
+		assert.Equal(t, 19, m.MatchCursor)
+		assert.Equal(t, 15, m.MatchOffset)
+		m = sazed.HomeMatchCursor(m)
+		assert.Equal(t, 0, m.MatchCursor)
+		assert.Equal(t, 0, m.MatchOffset)
+	})
+	t.Run("resize keeps cursor visible", func(t *testing.T) {
+		m := sazed.EndMatchCursor(sazed.Resize(model, 80, 0))
+		assert.Equal(t, 0, m.MatchOffset)
+		m = sazed.Resize(m, 80, 8)
+		assert.Equal(t, 18, m.MatchOffset)
+	})
+}
+
+func TestSetMatches(t *testing.T) {
+	matches := manyMatches(5)
+
+	t.Run("follows the selected memory", func(t *testing.T) {
+		model := newTestModel()
+		model = sazed.SetMatches(model, matches)
+		model.MatchCursor = 3
+
+		model = sazed.SetMatches(model, []sazed.Match{matches[4], matches[3], matches[0]})
+
+		assert.Equal(t, 1, model.MatchCursor)
+	})
+	t.Run("clamps if the selected memory is gone", func(t *testing.T) {
+		model := newTestModel()
+		model = sazed.SetMatches(model, matches)
+		model.MatchCursor = 3
+
+		model = sazed.SetMatches(model, []sazed.Match{matches[0], matches[1]})
+		assert.Equal(t, 1, model.MatchCursor)
+
+		model = sazed.SetMatches(model, []sazed.Match{})
+		assert.Equal(t, 0, model.MatchCursor)
+		_, ok := sazed.CursorMatch(model)
+		assert.False(t, ok)
+	})
+}
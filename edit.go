@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FocusedEditInput returns the index of the focused input in the edit page,
+// defaulting to the first one
+func FocusedEditInput(m Model) int {
+	for i, input := range m.EditTextInputs {
+		if input.Focused() {
+			return i
+		}
+	}
+	return 0
+}
+
+// MoveEditFocus moves the focus `delta` inputs, wrapping around the first and
+// last inputs
+func MoveEditFocus(m Model, delta int) (Model, tea.Cmd) {
+	if len(m.EditTextInputs) == 0 {
+		return m, nil
+	}
+	focused := FocusedEditInput(m)
+	next := ((focused+delta)%len(m.EditTextInputs) + len(m.EditTextInputs)) % len(m.EditTextInputs)
+	m.EditTextInputs[focused].Blur()
+	return m, m.EditTextInputs[next].Focus()
+}
+
+// SubmitEdit renders the selected memory with the values of all inputs and
+// submits it (see SubmitExecutions)
+func SubmitEdit(m Model) (Model, tea.Cmd) {
+	executions, err := RenderExecutions(m.SelectedMemory, m.GetPlaceholderValues())
+	if err != nil {
+		// The edit view shows the error, never output a broken command
+		return m, nil
+	}
+	return SubmitExecutions(m, executions)
+}
+
+// SubmitExecutions outputs the rendered `executions` of the selected memory
+// combined, or runs them one at a time if it's a workflow and --step is set.
+// Only memories with placeholders are reviewed first.
+func SubmitExecutions(m Model, executions []Execution) (Model, tea.Cmd) {
+	combined := CombineExecutions(executions, m.AppOpts.Separator)
+	if IsWorkflow(m.SelectedMemory) && m.AppOpts.Step {
+		if NeedsConfirm(m, combined.ShellCommand()) {
+			m, cmd := StartConfirm(m, combined)
+			m.ConfirmSteps = executions
+			return m, cmd
+		}
+		return StartSteps(m, executions)
+	}
+	if m.AppOpts.Review && NeedsEdit(m.SelectedMemory) {
+		return StartReview(m, combined)
+	}
+	return OutputExecution(m, combined)
+}
+
+// BackToSelect returns to the selection page, keeping the query and matches
+func BackToSelect(m Model) Model {
+	m.CurrentPage = PageSelect
+	m.SelectedMemory = Memory{}
+	m.SelectedMemories = nil
+	m.EditTextInputs = []textinput.Model{}
+	return m
+}
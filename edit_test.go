@@ -0,0 +1,63 @@
+package main_test
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func editModel() sazed.Model {
+	m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{memory1(), memory5()}))
+	m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("echo")})
+	return update(m, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestMoveEditFocus(t *testing.T) {
+	m := editModel()
+	assert.Equal(t, 0, sazed.FocusedEditInput(m))
+
+	m, _ = sazed.MoveEditFocus(m, 1)
+	assert.Equal(t, 1, sazed.FocusedEditInput(m))
+	m, _ = sazed.MoveEditFocus(m, 1)
+	assert.Equal(t, 0, sazed.FocusedEditInput(m))
+	m, _ = sazed.MoveEditFocus(m, -1)
+	assert.Equal(t, 1, sazed.FocusedEditInput(m))
+}
+
+func TestEditPageKeys(t *testing.T) {
+	t.Run("esc goes back keeping the query", func(t *testing.T) {
+		m := editModel()
+		assert.Equal(t, sazed.PageEdit, m.CurrentPage)
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, sazed.PageSelect, m.CurrentPage)
+		assert.Equal(t, "echo", m.SearchTextInput.Value())
+		assert.Equal(t, memory5(), m.Matches[m.MatchCursor].Memory)
+		assert.Empty(t, m.EditTextInputs)
+	})
+	t.Run("tab, shift+tab, up and down move between inputs", func(t *testing.T) {
+		m := editModel()
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, 1, sazed.FocusedEditInput(m))
+		m = update(m, tea.KeyMsg{Type: tea.KeyShiftTab})
+		assert.Equal(t, 0, sazed.FocusedEditInput(m))
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, 1, sazed.FocusedEditInput(m))
+		m = update(m, tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, 0, sazed.FocusedEditInput(m))
+	})
+	t.Run("ctrl+enter submits from any input", func(t *testing.T) {
+		defer cleanup()
+		m := editModel()
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("foo")})
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlJ})
+
+		assert.Equal(t, tea.QuitMsg{}, cmd())
+		assert.Equal(t, "echo foo  end", sazed.QuitOutput)
+	})
+}
@@ -0,0 +1,79 @@
+// This file contains the exec mode, which runs the chosen command instead of
+// outputting it (see the --exec option).
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// DefaultShell runs commands if $SHELL is not set
+const DefaultShell = "/bin/sh"
+
+// ShellFromEnv returns the user shell from $SHELL, or DefaultShell
+func ShellFromEnv(envMap map[string]string) string {
+	if shell := envMap["SHELL"]; shell != "" {
+		return shell
+	}
+	return DefaultShell
+}
+
+// ShellCmd returns the command running `execution` with `shell -c`, in its
+// working directory and environment. It's shared by --exec and workflow
+// steps, so both run commands the same way.
+func ShellCmd(shell string, execution Execution) *exec.Cmd {
+	cmd := exec.Command(shell, "-c", execution.Command)
+	cmd.Dir = execution.Cwd
+	cmd.Env = execution.Environ(os.Environ())
+	return cmd
+}
+
+// RunShell runs the `execution` command with `shell -c`, in its working
+// directory and environment, passing the given stdio through. Returns the
+// exit code of the command, or an error if it could not be run.
+//
+// Like a shell, sazed ignores SIGINT and SIGQUIT while the command runs, so
+// ctrl+c only stops the command and its exit code is still returned.
+func RunShell(shell string, execution Execution, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+	cmd := ShellCmd(shell, execution)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Catching the signals instead of ignoring them, since ignored signals are
+	// also ignored by the command
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitCode(exitErr), nil
+	}
+	if err != nil {
+		return 1, fmt.Errorf("failed to run %s: %w", shell, err)
+	}
+	return 0, nil
+}
+
+// exitCode returns the exit code of a finished command, or 128 plus the
+// signal number if it was killed by a signal, as shells do
+func exitCode(exitErr *exec.ExitError) int {
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// ExecAndExit runs `execution` in the user shell and exits with its exit code
+func ExecAndExit(execution Execution, envMap map[string]string) {
+	exitCode, err := RunShell(ShellFromEnv(envMap), execution, os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		exitWithErr("failed to execute command", err)
+	}
+	os.Exit(exitCode)
+}
@@ -0,0 +1,73 @@
+package main_test
+
+import (
+	"bytes"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestShellFromEnv(t *testing.T) {
+	assert.Equal(t, "/bin/zsh", sazed.ShellFromEnv(map[string]string{"SHELL": "/bin/zsh"}))
+	assert.Equal(t, sazed.DefaultShell, sazed.ShellFromEnv(map[string]string{}))
+}
+
+func TestShellCmd(t *testing.T) {
+	execution := sazed.Execution{Command: "ls", Cwd: "/tmp", Env: []sazed.EnvVar{{Name: "FOO", Value: "bar"}}}
+	cmd := sazed.ShellCmd("/bin/sh", execution)
+	assert.Equal(t, []string{"/bin/sh", "-c", "ls"}, cmd.Args)
+	assert.Equal(t, "/tmp", cmd.Dir)
+	assert.Equal(t, "FOO=bar", cmd.Env[len(cmd.Env)-1])
+}
+
+func TestRunShell(t *testing.T) {
+	t.Run("passes stdio through", func(t *testing.T) {
+		stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+		exitCode, err := sazed.RunShell("/bin/sh", sazed.Execution{Command: "cat; echo bar >&2"}, strings.NewReader("foo"), &stdout, &stderr)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, exitCode)
+		assert.Equal(t, "foo", stdout.String())
+		assert.Equal(t, "bar\n", stderr.String())
+	})
+	t.Run("applies the cwd and env", func(t *testing.T) {
+		stdout := bytes.Buffer{}
+		dir := t.TempDir()
+		execution := sazed.Execution{Command: `echo "$(pwd) $FOO"`, Cwd: dir, Env: []sazed.EnvVar{{Name: "FOO", Value: "bar"}}}
+
+		exitCode, err := sazed.RunShell("/bin/sh", execution, nil, &stdout, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, exitCode)
+		assert.Equal(t, dir+" bar\n", stdout.String())
+	})
+	t.Run("returns the exit code", func(t *testing.T) {
+		exitCode, err := sazed.RunShell("/bin/sh", sazed.Execution{Command: "exit 3"}, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, exitCode)
+	})
+	t.Run("returns 128 plus the signal if killed", func(t *testing.T) {
+		exitCode, err := sazed.RunShell("/bin/sh", sazed.Execution{Command: "kill -TERM $$"}, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 128+int(syscall.SIGTERM), exitCode)
+	})
+	t.Run("waits for the command on SIGINT", func(t *testing.T) {
+		stdout := bytes.Buffer{}
+		// The test process gets the SIGINT, like sazed does on ctrl+c
+		execution := sazed.Execution{Command: "kill -INT $PPID; sleep 0.1; echo done; exit 4"}
+
+		exitCode, err := sazed.RunShell("/bin/sh", execution, nil, &stdout, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4, exitCode)
+		assert.Equal(t, "done\n", stdout.String())
+	})
+	t.Run("errors if the shell can not run", func(t *testing.T) {
+		_, err := sazed.RunShell("/does/not/exist", sazed.Execution{Command: "ls"}, nil, nil, nil)
+		assert.ErrorContains(t, err, "failed to run /does/not/exist")
+	})
+}
@@ -0,0 +1,196 @@
+// This file contains the execution of a memory: the rendered command with
+// the working directory and environment it runs in.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// EnvVar is an environment variable set for a command
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// Execution is a rendered memory, ready to be output or run
+type Execution struct {
+	Command string
+	// Cwd is the directory to run the command from, if any
+	Cwd string
+	Env []EnvVar
+}
+
+// The execution to run when quitting (see QuitWithExecution)
+var QuitExecution Execution
+
+// QuitWithExecution quits with `execution` as output, written for the shell
+func QuitWithExecution(execution Execution) tea.Cmd {
+	return func() tea.Msg {
+		QuitExecution = execution
+		QuitOutput = execution.ShellCommand()
+		return tea.Quit()
+	}
+}
+
+// ShellCommand returns the command for a shell, in a subshell with the
+// working directory and environment if any: `(cd X && export A=b && cmd)`.
+// The variables are exported so every command of a pipeline or list sees
+// them, like when running with --exec.
+func (e Execution) ShellCommand() string {
+	if e.Cwd == "" && len(e.Env) == 0 {
+		return e.Command
+	}
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("(")
+	if e.Cwd != "" {
+		stringBuilder.WriteString("cd " + ShellQuote(e.Cwd) + " && ")
+	}
+	if len(e.Env) > 0 {
+		stringBuilder.WriteString("export")
+		for _, envVar := range e.Env {
+			stringBuilder.WriteString(" " + envVar.Name + "=" + ShellQuote(envVar.Value))
+		}
+		stringBuilder.WriteString(" && ")
+	}
+	stringBuilder.WriteString(e.Command)
+	stringBuilder.WriteString(")")
+	return stringBuilder.String()
+}
+
+// Environ returns `environ` with the execution environment added
+func (e Execution) Environ(environ []string) []string {
+	environ = slices.Clone(environ)
+	for _, envVar := range e.Env {
+		environ = append(environ, envVar.Name+"="+envVar.Value)
+	}
+	return environ
+}
+
+var shellSafeRegexp = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote quotes `s` for a POSIX shell, if needed
+func ShellQuote(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// envNames returns the names of the memory env vars, sorted
+func envNames(m Memory) []string {
+	names := make([]string, 0, len(m.Env))
+	for name := range m.Env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// MemoryTemplates returns the templates of the command (or each step of
+// workflows), cwd and env values (sorted by name) of `m`, in the order their
+// placeholders are edited
+func MemoryTemplates(m Memory) []Template {
+	templates := []Template{m.GetTemplate()}
+	if IsWorkflow(m) {
+		templates = []Template{}
+		for _, step := range m.Steps {
+			templates = append(templates, CompileTemplate(step.Command))
+		}
+	}
+	if m.Cwd != "" {
+		templates = append(templates, CompileTemplate(m.Cwd))
+	}
+	for _, name := range envNames(m) {
+		templates = append(templates, CompileTemplate(m.Env[name]))
+	}
+	return templates
+}
+
+// MemoryPlaceholders returns the placeholders of all MemoryTemplates and the
+// first parse error, if any
+func MemoryPlaceholders(m Memory) ([]Placeholder, error) {
+	placeholders := []Placeholder{}
+	var err error
+	for _, template := range MemoryTemplates(m) {
+		placeholders = append(placeholders, template.Placeholders...)
+		if err == nil {
+			err = template.Err
+		}
+	}
+	return placeholders, err
+}
+
+// RenderExecution renders the command, cwd and env of `m`, with the values
+// of MemoryPlaceholders in order
+func RenderExecution(m Memory, placeholderValues []string) (Execution, error) {
+	rendered := []string{}
+	for _, template := range MemoryTemplates(m) {
+		count := template.CountPlaceholders()
+		values := placeholderValues[:min(count, len(placeholderValues))]
+		placeholderValues = placeholderValues[len(values):]
+		s, err := template.Render(values)
+		if err != nil {
+			return Execution{}, err
+		}
+		rendered = append(rendered, s)
+	}
+
+	execution := Execution{Command: rendered[0]}
+	rendered = rendered[1:]
+	if m.Cwd != "" {
+		execution.Cwd = ExpandHome(rendered[0])
+		rendered = rendered[1:]
+	}
+	for i, name := range envNames(m) {
+		execution.Env = append(execution.Env, EnvVar{Name: name, Value: rendered[i]})
+	}
+	return execution, nil
+}
+
+// UnrenderedExecution returns the execution of `m` with the placeholders
+// kept, so it can be combined with other memories in a single command
+func UnrenderedExecution(m Memory) Execution {
+	if IsWorkflow(m) {
+		executions := make([]Execution, len(m.Steps))
+		for i, step := range m.Steps {
+			executions[i] = UnrenderedExecution(stepMemory(m, step))
+		}
+		return CombineExecutions(executions, JoinSeparatorAnd)
+	}
+	execution := Execution{Command: m.Command, Cwd: ExpandHome(m.Cwd)}
+	for _, name := range envNames(m) {
+		execution.Env = append(execution.Env, EnvVar{Name: name, Value: m.Env[name]})
+	}
+	return execution
+}
+
+// ExpandHome replaces a leading `~` in `path` by the home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// ValidateEnv checks that the env var names of `m` can be used in a shell
+func ValidateEnv(m Memory) error {
+	for name := range m.Env {
+		if !envNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid env var name %q", name)
+		}
+	}
+	return nil
+}
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
@@ -0,0 +1,118 @@
+package main_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func migrateMemory() sazed.Memory {
+	return sazed.CompileMemory(sazed.Memory{
+		Command: "make migrate {{target}}",
+		Cwd:     "/repos/{{repo}}",
+		Env:     map[string]string{"DATABASE_URL": "postgres://{{host}}/db", "DEBUG": "1"},
+	})
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "/foo/bar-1.txt", sazed.ShellQuote("/foo/bar-1.txt"))
+	assert.Equal(t, "'foo bar'", sazed.ShellQuote("foo bar"))
+	assert.Equal(t, `'it'\''s'`, sazed.ShellQuote("it's"))
+	assert.Equal(t, "''", sazed.ShellQuote(""))
+}
+
+func TestExecutionShellCommand(t *testing.T) {
+	assert.Equal(t, "ls", sazed.Execution{Command: "ls"}.ShellCommand())
+	assert.Equal(t, "(cd '/my dir' && ls)", sazed.Execution{Command: "ls", Cwd: "/my dir"}.ShellCommand())
+	execution := sazed.Execution{
+		Command: "ls",
+		Cwd:     "/tmp",
+		Env:     []sazed.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "x y"}},
+	}
+	assert.Equal(t, "(cd /tmp && export A=1 B='x y' && ls)", execution.ShellCommand())
+	assert.Equal(t, []string{"HOME=/", "A=1", "B=x y"}, execution.Environ([]string{"HOME=/"}))
+
+	run := func(execution sazed.Execution) string {
+		stdout := bytes.Buffer{}
+		_, err := sazed.RunShell("/bin/sh", sazed.Execution{Command: execution.ShellCommand()}, nil, &stdout, nil)
+		assert.Nil(t, err)
+		return stdout.String()
+	}
+	t.Run("env applies to compound commands", func(t *testing.T) {
+		env := []sazed.EnvVar{{Name: "A", Value: "1"}}
+		assert.Equal(t, "1\n1\n", run(sazed.Execution{Command: `sh -c 'echo $A' && sh -c 'echo $A'`, Env: env}))
+		assert.Equal(t, "1\n", run(sazed.Execution{Command: `true | sh -c 'echo $A'`, Env: env}))
+	})
+	t.Run("env is set before the command expands", func(t *testing.T) {
+		assert.Equal(t, "x y\n", run(sazed.Execution{Command: "echo $A", Env: []sazed.EnvVar{{Name: "A", Value: "x y"}}}))
+	})
+}
+
+func TestMemoryPlaceholders(t *testing.T) {
+	placeholders, err := sazed.MemoryPlaceholders(migrateMemory())
+	assert.Nil(t, err)
+	names := []string{}
+	for _, placeholder := range placeholders {
+		names = append(names, placeholder.Name)
+	}
+	assert.Equal(t, []string{"target", "repo", "host"}, names)
+
+	_, err = sazed.MemoryPlaceholders(sazed.Memory{Command: "ls", Cwd: "{{foo"})
+	assert.ErrorContains(t, err, "unterminated placeholder")
+}
+
+func TestRenderExecution(t *testing.T) {
+	execution, err := sazed.RenderExecution(migrateMemory(), []string{"up", "api", "localhost"})
+	assert.Nil(t, err)
+	assert.Equal(t, sazed.Execution{
+		Command: "make migrate up",
+		Cwd:     "/repos/api",
+		Env: []sazed.EnvVar{
+			{Name: "DATABASE_URL", Value: "postgres://localhost/db"},
+			{Name: "DEBUG", Value: "1"},
+		},
+	}, execution)
+
+	execution, err = sazed.RenderExecution(sazed.Memory{Command: "ls"}, []string{})
+	assert.Nil(t, err)
+	assert.Equal(t, sazed.Execution{Command: "ls"}, execution)
+}
+
+func TestExpandHome(t *testing.T) {
+	homeDir, _ := os.UserHomeDir()
+	assert.Equal(t, path.Join(homeDir, "repo"), sazed.ExpandHome("~/repo"))
+	assert.Equal(t, homeDir, sazed.ExpandHome("~"))
+	assert.Equal(t, "/tmp/~", sazed.ExpandHome("/tmp/~"))
+	assert.Equal(t, "~foo", sazed.ExpandHome("~foo"))
+}
+
+func TestValidateEnv(t *testing.T) {
+	assert.Nil(t, sazed.ValidateEnv(migrateMemory()))
+	err := sazed.ValidateEnv(sazed.Memory{Env: map[string]string{"A B": "1"}})
+	assert.ErrorContains(t, err, `invalid env var name "A B"`)
+}
+
+func TestEditExecution(t *testing.T) {
+	defer cleanup()
+	m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{migrateMemory()}))
+	m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Len(t, m.EditTextInputs, 3)
+	for _, value := range []string{"new", "api", "db.local"} {
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+	lines := strings.Split(sazed.ViewCommandEdit(m), "\n")
+	assert.Equal(t, "Command: (cd /repos/api && export DATABASE_URL=postgres://db.local/db DEBUG=1 && make migrate new)", lines[0])
+
+	update(m, tea.KeyMsg{Type: tea.KeyCtrlJ})
+
+	assert.Equal(t, "(cd /repos/api && export DATABASE_URL=postgres://db.local/db DEBUG=1 && make migrate new)", sazed.QuitOutput)
+	assert.Equal(t, "/repos/api", sazed.QuitExecution.Cwd)
+	assert.Equal(t, "make migrate new", sazed.QuitExecution.Command)
+}
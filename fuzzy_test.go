@@ -22,6 +22,7 @@ func TestGetMatches(t *testing.T) {
 			{
 				Memory:                memories[0],
 				Score:                 30,
+				CommandScore:          30,
 				CommandMatchedIndexes: []int{0, 1, 2},
 			},
 		}, matches)
@@ -37,11 +38,14 @@ func TestGetMatches(t *testing.T) {
 			{
 				Memory:                memories[0],
 				Score:                 30,
+				CommandScore:          30,
 				CommandMatchedIndexes: []int{0, 1, 2},
 			},
 			{
 				Memory:                memories[2],
+				Index:                 2,
 				Score:                 21,
+				CommandScore:          21,
 				CommandMatchedIndexes: []int{4, 5, 6},
 			},
 		}, matches)
@@ -56,11 +60,14 @@ func TestGetMatches(t *testing.T) {
 			{
 				Memory:                memories[0],
 				Score:                 30,
+				CommandScore:          30,
 				CommandMatchedIndexes: []int{0, 1, 2},
 			},
 			{
 				Memory:                    memories[1],
+				Index:                     1,
 				Score:                     29,
+				DescriptionScore:          29,
 				DescriptionMatchedIndexes: []int{0, 1, 2},
 			},
 		}, matches)
@@ -73,7 +80,30 @@ func TestGetMatches(t *testing.T) {
 		matches := sazed.NewFuzzy().GetMatches(memories, "")
 		assert.Equal(t, []sazed.Match{
 			{Memory: memories[0]},
-			{Memory: memories[1]},
+			{Memory: memories[1], Index: 1},
 		}, matches)
 	})
+	t.Run("extended query syntax", func(t *testing.T) {
+		memories := []sazed.Memory{
+			{Command: "docker rm foo", Description: "Remove a container"},
+			{Command: "docker compose rm", Description: "Remove compose containers"},
+			{Command: "docker ps", Description: "List containers"},
+		}
+		matches := sazed.NewFuzzy().GetMatches(memories, "docker !compose desc:remove")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[0], matches[0].Memory)
+	})
+	t.Run("equal scores keep file order", func(t *testing.T) {
+		memories := []sazed.Memory{
+			{Command: "bar"},
+			{Command: "foo 1"},
+			{Command: "foo 2"},
+			{Command: "foo 3"},
+		}
+		for i := 0; i < 10; i++ {
+			matches := sazed.NewFuzzy().GetMatches(memories, "foo")
+			assert.Len(t, matches, 3)
+			assert.Equal(t, []int{1, 2, 3}, []int{matches[0].Index, matches[1].Index, matches[2].Index})
+		}
+	})
 }
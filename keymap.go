@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyStyle is a predefined set of keybindings
+type KeyStyle string
+
+const (
+	KeyStyleDefault KeyStyle = "default"
+	KeyStyleEmacs   KeyStyle = "emacs"
+	KeyStyleVim     KeyStyle = "vim"
+)
+
+// KeyAction is the name of an action that can be bound to keys
+type KeyAction string
+
+const (
+	KeyActionQuit            KeyAction = "quit"
+	KeyActionUp              KeyAction = "up"
+	KeyActionDown            KeyAction = "down"
+	KeyActionPageUp          KeyAction = "page-up"
+	KeyActionPageDown        KeyAction = "page-down"
+	KeyActionHome            KeyAction = "home"
+	KeyActionEnd             KeyAction = "end"
+	KeyActionSelect          KeyAction = "select"
+	KeyActionCycleSearchMode KeyAction = "cycle-search-mode"
+	KeyActionTogglePreview   KeyAction = "toggle-preview"
+	KeyActionToggleShowAll   KeyAction = "toggle-show-all"
+	KeyActionSubmit          KeyAction = "submit"
+	KeyActionSubmitAll       KeyAction = "submit-all"
+	KeyActionNextInput       KeyAction = "next-input"
+	KeyActionPreviousInput   KeyAction = "previous-input"
+	KeyActionBack            KeyAction = "back"
+	KeyActionSaveMemory      KeyAction = "save-memory"
+	KeyActionMark            KeyAction = "mark"
+)
+
+// keyActionsHelp is the help text of each action, in the order they are shown
+var keyActionsHelp = []struct {
+	Action KeyAction
+	Help   string
+}{
+	{KeyActionUp, "up"},
+	{KeyActionDown, "down"},
+	{KeyActionPageUp, "page up"},
+	{KeyActionPageDown, "page down"},
+	{KeyActionHome, "first"},
+	{KeyActionEnd, "last"},
+	{KeyActionMark, "mark"},
+	{KeyActionSelect, "select"},
+	{KeyActionSubmit, "submit"},
+	{KeyActionSubmitAll, "submit all"},
+	{KeyActionNextInput, "next"},
+	{KeyActionPreviousInput, "previous"},
+	{KeyActionBack, "back"},
+	{KeyActionSaveMemory, "save as memory"},
+	{KeyActionCycleSearchMode, "search mode"},
+	{KeyActionTogglePreview, "preview"},
+	{KeyActionToggleShowAll, "show all"},
+	{KeyActionQuit, "quit"},
+}
+
+// KeysConfig is the keybindings section of the config file
+type KeysConfig struct {
+	// Style is the base set of keybindings (default, emacs or vim)
+	Style KeyStyle `yaml:"style"`
+	// Bindings replaces the keys of actions, like `quit: [ctrl+c, esc]`
+	Bindings map[KeyAction][]string `yaml:"bindings"`
+}
+
+// KeyMap has the keybindings for each action
+type KeyMap struct {
+	Quit            key.Binding
+	Up              key.Binding
+	Down            key.Binding
+	PageUp          key.Binding
+	PageDown        key.Binding
+	Home            key.Binding
+	End             key.Binding
+	Select          key.Binding
+	CycleSearchMode key.Binding
+	TogglePreview   key.Binding
+	ToggleShowAll   key.Binding
+	Submit          key.Binding
+	SubmitAll       key.Binding
+	NextInput       key.Binding
+	PreviousInput   key.Binding
+	Back            key.Binding
+	SaveMemory      key.Binding
+	Mark            key.Binding
+}
+
+// styleKeys returns the keys of each action for `style`. Only `ctrl+c` quits
+// by default, so that any letter can be typed in a query.
+func styleKeys(style KeyStyle) (map[KeyAction][]string, error) {
+	keys := map[KeyAction][]string{
+		KeyActionQuit:            {"ctrl+c"},
+		KeyActionUp:              {"up"},
+		KeyActionDown:            {"down"},
+		KeyActionPageUp:          {"pgup"},
+		KeyActionPageDown:        {"pgdown"},
+		KeyActionHome:            {"home"},
+		KeyActionEnd:             {"end"},
+		KeyActionSelect:          {"enter"},
+		KeyActionCycleSearchMode: {"ctrl+t"},
+		KeyActionTogglePreview:   {"ctrl+o"},
+		KeyActionToggleShowAll:   {"ctrl+l"},
+		KeyActionSubmit:          {"enter"},
+		// Most terminals send ctrl+j for ctrl+enter
+		KeyActionSubmitAll:     {"ctrl+j"},
+		KeyActionNextInput:     {"tab", "down"},
+		KeyActionPreviousInput: {"shift+tab", "up"},
+		KeyActionBack:          {"esc"},
+		KeyActionSaveMemory:    {"ctrl+s"},
+		KeyActionMark:          {"tab"},
+	}
+	switch style {
+	case "", KeyStyleDefault:
+	case KeyStyleEmacs:
+		keys[KeyActionQuit] = append(keys[KeyActionQuit], "ctrl+g")
+		keys[KeyActionUp] = append(keys[KeyActionUp], "ctrl+p")
+		keys[KeyActionDown] = append(keys[KeyActionDown], "ctrl+n")
+		keys[KeyActionPageUp] = append(keys[KeyActionPageUp], "alt+v")
+		keys[KeyActionPageDown] = append(keys[KeyActionPageDown], "ctrl+v")
+		keys[KeyActionHome] = append(keys[KeyActionHome], "alt+<")
+		keys[KeyActionEnd] = append(keys[KeyActionEnd], "alt+>")
+	case KeyStyleVim:
+		// Plain letters are typed in the query, so vim motions use ctrl
+		keys[KeyActionUp] = append(keys[KeyActionUp], "ctrl+k")
+		keys[KeyActionDown] = append(keys[KeyActionDown], "ctrl+j")
+		keys[KeyActionPageUp] = append(keys[KeyActionPageUp], "ctrl+u")
+		keys[KeyActionPageDown] = append(keys[KeyActionPageDown], "ctrl+d")
+	default:
+		return nil, fmt.Errorf("invalid key style: %s", style)
+	}
+	return keys, nil
+}
+
+// NewKeyMap returns the keybindings of the `config` style, with the keys of
+// the configured actions replaced.
+func NewKeyMap(config KeysConfig) (KeyMap, error) {
+	keys, err := styleKeys(config.Style)
+	if err != nil {
+		return KeyMap{}, err
+	}
+	for action, actionKeys := range config.Bindings {
+		if _, ok := keys[action]; !ok {
+			return KeyMap{}, fmt.Errorf("invalid key action: %s", action)
+		}
+		keys[action] = actionKeys
+	}
+
+	bindings := map[KeyAction]key.Binding{}
+	for _, actionHelp := range keyActionsHelp {
+		actionKeys := keys[actionHelp.Action]
+		bindings[actionHelp.Action] = key.NewBinding(
+			key.WithKeys(actionKeys...),
+			key.WithHelp(strings.Join(actionKeys, "/"), actionHelp.Help),
+		)
+	}
+	return KeyMap{
+		Quit:            bindings[KeyActionQuit],
+		Up:              bindings[KeyActionUp],
+		Down:            bindings[KeyActionDown],
+		PageUp:          bindings[KeyActionPageUp],
+		PageDown:        bindings[KeyActionPageDown],
+		Home:            bindings[KeyActionHome],
+		End:             bindings[KeyActionEnd],
+		Select:          bindings[KeyActionSelect],
+		CycleSearchMode: bindings[KeyActionCycleSearchMode],
+		TogglePreview:   bindings[KeyActionTogglePreview],
+		ToggleShowAll:   bindings[KeyActionToggleShowAll],
+		Submit:          bindings[KeyActionSubmit],
+		SubmitAll:       bindings[KeyActionSubmitAll],
+		NextInput:       bindings[KeyActionNextInput],
+		PreviousInput:   bindings[KeyActionPreviousInput],
+		Back:            bindings[KeyActionBack],
+		SaveMemory:      bindings[KeyActionSaveMemory],
+		Mark:            bindings[KeyActionMark],
+	}, nil
+}
+
+// DefaultKeyMap returns the keybindings of the default style
+func DefaultKeyMap() KeyMap {
+	keyMap, _ := NewKeyMap(KeysConfig{})
+	return keyMap
+}
+
+// PageKeyBindings returns the bindings active in `page`, shown in the help
+// footer
+func PageKeyBindings(keyMap KeyMap, page Page) []key.Binding {
+	if page == PageSteps {
+		return []key.Binding{keyMap.Submit, keyMap.NextInput, keyMap.PreviousInput, keyMap.Quit}
+	}
+	if page == PageConfirm {
+		return []key.Binding{keyMap.Submit, keyMap.Back, keyMap.Quit}
+	}
+	if page == PageReview {
+		return []key.Binding{keyMap.Submit, keyMap.SaveMemory, keyMap.Back, keyMap.Quit}
+	}
+	if page == PageEdit {
+		return []key.Binding{
+			keyMap.Submit,
+			keyMap.SubmitAll,
+			keyMap.NextInput,
+			keyMap.PreviousInput,
+			keyMap.Back,
+			keyMap.Quit,
+		}
+	}
+	return []key.Binding{
+		keyMap.Up,
+		keyMap.Down,
+		keyMap.Mark,
+		keyMap.Select,
+		keyMap.CycleSearchMode,
+		keyMap.TogglePreview,
+		keyMap.ToggleShowAll,
+		keyMap.Quit,
+	}
+}
@@ -0,0 +1,61 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	ctrl := func(keyType tea.KeyType) tea.KeyMsg { return tea.KeyMsg{Type: keyType} }
+	t.Run("default", func(t *testing.T) {
+		keyMap, err := sazed.NewKeyMap(sazed.KeysConfig{})
+		assert.Nil(t, err)
+		assert.True(t, key.Matches(ctrl(tea.KeyDown), keyMap.Down))
+		assert.False(t, key.Matches(ctrl(tea.KeyCtrlN), keyMap.Down))
+		assert.False(t, key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}, keyMap.Quit))
+		assert.Equal(t, sazed.DefaultKeyMap(), keyMap)
+	})
+	t.Run("emacs", func(t *testing.T) {
+		keyMap, err := sazed.NewKeyMap(sazed.KeysConfig{Style: sazed.KeyStyleEmacs})
+		assert.Nil(t, err)
+		assert.True(t, key.Matches(ctrl(tea.KeyCtrlN), keyMap.Down))
+		assert.True(t, key.Matches(ctrl(tea.KeyCtrlP), keyMap.Up))
+		assert.True(t, key.Matches(ctrl(tea.KeyDown), keyMap.Down))
+		assert.Equal(t, "down/ctrl+n", keyMap.Down.Help().Key)
+	})
+	t.Run("vim", func(t *testing.T) {
+		keyMap, err := sazed.NewKeyMap(sazed.KeysConfig{Style: sazed.KeyStyleVim})
+		assert.Nil(t, err)
+		assert.True(t, key.Matches(ctrl(tea.KeyCtrlJ), keyMap.Down))
+		assert.True(t, key.Matches(ctrl(tea.KeyCtrlK), keyMap.Up))
+	})
+	t.Run("replaces bindings", func(t *testing.T) {
+		keyMap, err := sazed.NewKeyMap(sazed.KeysConfig{
+			Style:    sazed.KeyStyleEmacs,
+			Bindings: map[sazed.KeyAction][]string{sazed.KeyActionQuit: {"esc"}},
+		})
+		assert.Nil(t, err)
+		assert.True(t, key.Matches(ctrl(tea.KeyEsc), keyMap.Quit))
+		assert.False(t, key.Matches(ctrl(tea.KeyCtrlC), keyMap.Quit))
+		assert.True(t, key.Matches(ctrl(tea.KeyCtrlN), keyMap.Down))
+	})
+	t.Run("invalid style", func(t *testing.T) {
+		_, err := sazed.NewKeyMap(sazed.KeysConfig{Style: "foo"})
+		assert.ErrorContains(t, err, "invalid key style: foo")
+	})
+	t.Run("invalid action", func(t *testing.T) {
+		_, err := sazed.NewKeyMap(sazed.KeysConfig{Bindings: map[sazed.KeyAction][]string{"foo": {"a"}}})
+		assert.ErrorContains(t, err, "invalid key action: foo")
+	})
+}
+
+func TestPageKeyBindings(t *testing.T) {
+	keyMap := sazed.DefaultKeyMap()
+	assert.Contains(t, sazed.PageKeyBindings(keyMap, sazed.PageSelect), keyMap.CycleSearchMode)
+	assert.Contains(t, sazed.PageKeyBindings(keyMap, sazed.PageEdit), keyMap.Back)
+	assert.NotContains(t, sazed.PageKeyBindings(keyMap, sazed.PageEdit), keyMap.CycleSearchMode)
+}
@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -18,12 +23,36 @@ import (
 const DefaultCommandPrintLength = 75
 
 type AppOptions struct {
-	MemoriesFile       string `env:"SAZED_MEMORIES_FILE"`
-	CommandPrintLength int    `env:"SAZED_COMMAND_PRINT_LENGTH"`
+	MemoriesFile       string        `env:"SAZED_MEMORIES_FILE"`
+	CommandPrintLength int           `env:"SAZED_COMMAND_PRINT_LENGTH"`
+	SearchMode         SearchMode    `env:"SAZED_SEARCH_MODE"`
+	UsageFile          string        `env:"SAZED_USAGE_FILE"`
+	CommandWeight      float64       `env:"SAZED_COMMAND_WEIGHT" envDefault:"1"`
+	DescriptionWeight  float64       `env:"SAZED_DESCRIPTION_WEIGHT" envDefault:"1"`
+	TieBreak           TieBreak      `env:"SAZED_TIE_BREAK"`
+	ExplainScores      bool          `env:"SAZED_EXPLAIN_SCORES"`
+	ConfigFile         string        `env:"SAZED_CONFIG_FILE"`
+	SmartCase          bool          `env:"SAZED_SMART_CASE" envDefault:"true"`
+	FoldAccents        bool          `env:"SAZED_FOLD_ACCENTS" envDefault:"true"`
+	Review             bool          `env:"SAZED_REVIEW" envDefault:"true"`
+	Separator          JoinSeparator `env:"SAZED_SEPARATOR"`
+	Output             string        `env:"SAZED_OUTPUT"`
+	Exec               bool          `env:"SAZED_EXEC"`
+	Step               bool          `env:"SAZED_STEP"`
+
+	// Config is loaded from ConfigFile (see LoadConfigFile)
+	Config Config
 }
 
 // ParseAppOptions parses the app options from CLI Arguments a map of environmental variables
 func ParseAppOptions(cliArgs []string, envMap map[string]string) (AppOptions, error) {
+	flagSet := flag.NewFlagSet("sazed", flag.ContinueOnError)
+	return parseAppOptions(flagSet, cliArgs, envMap)
+}
+
+// parseAppOptions parses the app options using `flagSet`, which may already
+// have other (e.g. subcommand) flags registered.
+func parseAppOptions(flagSet *flag.FlagSet, cliArgs []string, envMap map[string]string) (AppOptions, error) {
 	// parse env vars
 	var opts AppOptions
 	err := env.ParseWithOptions(&opts, env.Options{Environment: envMap})
@@ -32,13 +61,32 @@ func ParseAppOptions(cliArgs []string, envMap map[string]string) (AppOptions, er
 	}
 
 	// parse CLI options
-	flagSet := flag.NewFlagSet("sazed", flag.ContinueOnError)
 	flagSet.StringVar(&opts.MemoriesFile, "memories-file", opts.MemoriesFile, "File to read memories from")
 	flagSet.IntVar(&opts.CommandPrintLength, "command-print-length", opts.CommandPrintLength, "How many characters to print for Commands")
+	searchMode := string(opts.SearchMode)
+	flagSet.StringVar(&searchMode, "search-mode", searchMode, "How to search memories (fuzzy, substring, prefix, regex or typo)")
+	flagSet.StringVar(&opts.UsageFile, "usage-file", opts.UsageFile, "File to store how many times each memory was used")
+	flagSet.Float64Var(&opts.CommandWeight, "command-weight", opts.CommandWeight, "Weight of the command score when ranking matches")
+	flagSet.Float64Var(&opts.DescriptionWeight, "description-weight", opts.DescriptionWeight, "Weight of the description score when ranking matches")
+	tieBreak := string(opts.TieBreak)
+	flagSet.StringVar(&tieBreak, "tie-break", tieBreak, "How to order matches with equal scores (order, length or usage)")
+	flagSet.BoolVar(&opts.ExplainScores, "explain-scores", opts.ExplainScores, "Show the score breakdown of each match")
+	flagSet.StringVar(&opts.ConfigFile, "config-file", opts.ConfigFile, "File to read the config from")
+	flagSet.BoolVar(&opts.SmartCase, "smart-case", opts.SmartCase, "Ignore case unless the query has uppercase letters")
+	flagSet.BoolVar(&opts.FoldAccents, "fold-accents", opts.FoldAccents, "Ignore accents and other diacritics when searching")
+	flagSet.BoolVar(&opts.Review, "review", opts.Review, "Review and edit the command before it's output (--review=false to skip it)")
+	separator := string(opts.Separator)
+	flagSet.StringVar(&separator, "separator", separator, "How to join the commands of marked memories (&&, ; or newline)")
+	flagSet.StringVar(&opts.Output, "output", opts.Output, "Where to output the command (stdout, fd:N, file:PATH, osc52 or tmux-buffer)")
+	flagSet.BoolVar(&opts.Exec, "exec", opts.Exec, "Run the command with $SHELL -c instead of outputting it")
+	flagSet.BoolVar(&opts.Step, "step", opts.Step, "Run the steps of workflows one at a time")
 	err = flagSet.Parse(cliArgs)
 	if err != nil {
 		return opts, fmt.Errorf("failed to parse cli args: %w", err)
 	}
+	opts.SearchMode = SearchMode(searchMode)
+	opts.TieBreak = TieBreak(tieBreak)
+	opts.Separator = JoinSeparator(separator)
 
 	// defaults
 	if opts.CommandPrintLength == 0 {
@@ -48,6 +96,49 @@ func ParseAppOptions(cliArgs []string, envMap map[string]string) (AppOptions, er
 		homeDir, _ := os.UserHomeDir()
 		opts.MemoriesFile = path.Join(homeDir, ".config/sazed/memories.yaml")
 	}
+	if opts.SearchMode == "" {
+		opts.SearchMode = SearchModeFuzzy
+	}
+	if opts.UsageFile == "" {
+		homeDir, _ := os.UserHomeDir()
+		opts.UsageFile = path.Join(homeDir, ".local/state/sazed/usage.yaml")
+	}
+	if opts.TieBreak == "" {
+		opts.TieBreak = TieBreakOrder
+	}
+	if opts.Separator == "" {
+		opts.Separator = JoinSeparatorAnd
+	}
+	if opts.Output == "" {
+		opts.Output = "stdout"
+	}
+	if opts.ConfigFile == "" {
+		homeDir, _ := os.UserHomeDir()
+		opts.ConfigFile = path.Join(homeDir, ".config/sazed/config.yaml")
+	}
+
+	// validations
+	if _, err := ParseSearchMode(string(opts.SearchMode)); err != nil {
+		return opts, err
+	}
+	if _, err := ParseTieBreak(string(opts.TieBreak)); err != nil {
+		return opts, err
+	}
+	if _, err := ParseJoinSeparator(string(opts.Separator)); err != nil {
+		return opts, err
+	}
+	if _, _, err := ParseOutput(opts.Output); err != nil {
+		return opts, err
+	}
+	if opts.Exec && opts.Output != "stdout" {
+		return opts, fmt.Errorf("--exec can not be used with --output")
+	}
+	if opts.CommandWeight < 0 || opts.DescriptionWeight < 0 {
+		return opts, fmt.Errorf("weights can not be negative")
+	}
+	if opts.CommandWeight == 0 && opts.DescriptionWeight == 0 {
+		return opts, fmt.Errorf("weights can not both be zero")
+	}
 
 	return opts, nil
 }
@@ -67,16 +158,57 @@ var QuitOutput string
 
 // Quits the program with an output
 func QuitWithOutput(output string) tea.Cmd {
-	return func() tea.Msg {
-		QuitOutput = output
-		return tea.Quit()
-	}
+	return QuitWithExecution(Execution{Command: output})
 }
 
 // Memory represents a memorized CLI command with it's context.
 type Memory struct {
+	ID          string
 	Command     string
 	Description string
+	Keywords    []string
+	// Confirm asks for confirmation before outputting the command
+	Confirm bool
+	// Cwd is the directory to run the command from
+	Cwd string
+	// Env are environment variables to run the command with
+	Env map[string]string
+	// Steps make the memory a workflow (see IsWorkflow)
+	Steps []Step
+	// When are the conditions for the memory to be shown, if any
+	When *When
+
+	// Template is the compiled Command. It's set when memories are loaded.
+	Template *Template `yaml:"-"`
+	// Folded is the accent folded text (see FoldMemory). It's set when
+	// memories are loaded.
+	Folded *FoldedText `yaml:"-"`
+	// Usage is how many times the memory was selected (see LoadUsage)
+	Usage int `yaml:"-"`
+	// Source is the file the memory was loaded from
+	Source string `yaml:"-"`
+	// OutOfContext is set if the When conditions don't hold (see ApplyWhen)
+	OutOfContext bool `yaml:"-"`
+}
+
+// GetTemplate returns the compiled Command, compiling it if needed.
+func (m Memory) GetTemplate() Template {
+	if m.Template != nil {
+		return *m.Template
+	}
+	return CompileTemplate(m.Command)
+}
+
+// CompileMemory returns the memory with it's Command compiled and text
+// folded
+func CompileMemory(m Memory) Memory {
+	if IsWorkflow(m) && m.Command == "" {
+		m.Command = WorkflowCommand(m.Steps)
+	}
+	template := CompileTemplate(m.Command)
+	m.Template = &template
+	m.Folded = FoldMemory(m)
+	return m
 }
 
 // Page represents the possible pages the user is interacting with
@@ -84,22 +216,66 @@ type Page string
 
 const PageSelect Page = "PageSelect"
 const PageEdit Page = "PageEdit"
+const PageReview Page = "PageReview"
+const PageConfirm Page = "PageConfirm"
+const PageSteps Page = "PageSteps"
 
 // Basic Model for https://github.com/charmbracelet/bubbletea
 type Model struct {
 	// Models & Updaters
 	SearchTextInput textinput.Model
 	EditTextInputs  []textinput.Model
-	UpdateMatches   func(m Model, cleanCache bool) Model
+	Help            help.Model
 	LoadMemories    func(AppOptions) tea.Cmd
+	Searcher        IFuzzy
+
+	// Review page (see StartReview)
+	ReviewExecution        Execution
+	ReviewTextArea         textarea.Model
+	ReviewDescriptionInput textinput.Model
+	ReviewSave             bool
+
+	// Confirm page (see StartConfirm)
+	ConfirmInput        textinput.Model
+	ConfirmExecution    Execution
+	ConfirmPreviousPage Page
+	// ConfirmSteps are run one at a time once confirmed, if set
+	ConfirmSteps []Execution
+
+	// Steps page (see StartSteps)
+	Steps        []Execution
+	StepStatuses []StepStatus
+	StepCursor   int
+	RunStep      func(index int, execution Execution) tea.Cmd
 
 	// Fields
 	AppOpts        AppOptions
 	Memories       []Memory
 	Matches        []Match
 	MatchCursor    int
+	MatchOffset    int
 	CurrentPage    Page
 	SelectedMemory Memory
+	// SelectedMemories are the memories combined in SelectedMemory
+	SelectedMemories []Memory
+	// MarkedMemories are the indexes of the marked memories, in order
+	MarkedMemories []int
+	SearchMode     SearchMode
+	SearchOptions  SearchOptions
+	ShowPreview    bool
+	Keys           KeyMap
+	// NewMemory is saved to the memories file when quitting, if set
+	NewMemory Memory
+
+	// Terminal size, zero until known
+	Width  int
+	Height int
+
+	// Asynchronous search state (see ScheduleSearch)
+	SearchDebounce time.Duration
+	SearchInput    string
+	SearchSeq      int
+	CancelSearchFn context.CancelFunc
 }
 
 // Returns the initial model
@@ -108,14 +284,27 @@ func InitialModel(cliOpts AppOptions) Model {
 	textInput.Focus()
 	textInput.Cursor.SetMode(cursor.CursorStatic)
 
-	fuzzy := NewFuzzy()
+	searchMode := cliOpts.SearchMode
+	if searchMode == "" {
+		searchMode = SearchModeFuzzy
+	}
+	searchOptions := NewSearchOptions(cliOpts)
+	searcher := NewSearcher(searchMode, searchOptions)
+
+	// The keys are validated when loading the config file
+	keyMap, err := NewKeyMap(cliOpts.Config.Keys)
+	if err != nil {
+		keyMap = DefaultKeyMap()
+	}
 
 	return Model{
 		// Models & Updaters
 		SearchTextInput: textInput,
 		EditTextInputs:  []textinput.Model{},
-		UpdateMatches:   UpdateMatches(fuzzy),
+		Help:            help.New(),
 		LoadMemories:    InitLoadMemories,
+		RunStep:         RunStepInShell,
+		Searcher:        searcher,
 
 		// Fields
 		CurrentPage:    PageSelect,
@@ -124,6 +313,10 @@ func InitialModel(cliOpts AppOptions) Model {
 		Matches:        []Match{},
 		MatchCursor:    0,
 		SelectedMemory: Memory{},
+		SearchMode:     searchMode,
+		SearchOptions:  searchOptions,
+		SearchDebounce: DefaultSearchDebounce,
+		Keys:           keyMap,
 	}
 }
 
@@ -132,54 +325,86 @@ type LoadedMemories []Memory
 func LoadMemoriesFromYaml(source io.Reader) ([]Memory, error) {
 	memories := []Memory{}
 	err := yaml.NewDecoder(source).Decode(&memories)
+	if err == nil {
+		memories, err = ExpandReferences(memories)
+	}
+	for i := range memories {
+		memories[i] = CompileMemory(memories[i])
+		if err == nil {
+			err = ValidateEnv(memories[i])
+		}
+	}
 	return memories, err
 }
 
+// LoadMemoriesFile reads the memories from the yaml file at `path`
+func LoadMemoriesFile(path string) ([]Memory, error) {
+	memoriesFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memoriesFile: %w", err)
+	}
+	defer memoriesFile.Close()
+	memories, err := LoadMemoriesFromYaml(memoriesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories from yaml: %w", err)
+	}
+	for i := range memories {
+		memories[i].Source = path
+	}
+	return memories, nil
+}
+
 func InitLoadMemories(cliOpts AppOptions) tea.Cmd {
 	return func() tea.Msg {
-		memoriesFile, err := os.Open(cliOpts.MemoriesFile)
+		memories, err := LoadMemoriesFile(cliOpts.MemoriesFile)
 		if err != nil {
-			return QuitWithErr(fmt.Errorf("failed to load memoriesFile: %w", err))
+			return QuitWithErr(err)
 		}
-		memories, err := LoadMemoriesFromYaml(memoriesFile)
-		if err != nil {
-			return QuitWithErr(fmt.Errorf("failed to load memories from yaml: %w", err))
+		if RecordsUsage(cliOpts) {
+			usage, err := LoadUsage(cliOpts.UsageFile)
+			if err != nil {
+				return QuitWithErr(err)
+			}
+			memories = SetUsage(memories, usage)
 		}
-		return LoadedMemories(memories)
+		return LoadedMemories(ApplyWhen(memories, NewWhenContext()))
 	}
 }
 
-type SetMatched []Match
-
-// UpdateMatches recalculate the matches for the given user input and list of memories
-func UpdateMatches(fuzzy IFuzzy) func(m Model, cleanCache bool) Model {
-	first := true
-	inputCache := ""
-	return func(m Model, cleanCache bool) Model {
-		memories := m.Memories
-		input := m.SearchTextInput.Value()
-		if cleanCache {
-			first = true
-			inputCache = ""
-		}
-		if !first {
-			if input == inputCache {
-				return m
-			}
-		}
-		first = false
-		inputCache = input
-		m.Matches = fuzzy.GetMatches(memories, input)
-		return m
-	}
+// CycleSearchMode changes to the next search mode and recalculates the matches
+func CycleSearchMode(m Model) Model {
+	m.SearchMode = NextSearchMode(m.SearchMode)
+	return ResetSearcher(m)
+}
+
+// ResetSearcher recreates the searcher after the search mode or options
+// changed and recalculates the matches
+func ResetSearcher(m Model) Model {
+	m.Searcher = NewSearcher(m.SearchMode, m.SearchOptions)
+	return UpdateMatchesNow(m)
 }
 
 // SelectCursorMemory is the logic fo when a new memory is selected based on
-// existing cursor.
+// existing cursor. Does nothing if there are no matches.
 func SelectCursorMemory(m Model) (newModel Model, quitCmd tea.Cmd) {
-	m.SelectedMemory = m.Matches[m.MatchCursor].Memory
+	if len(m.MarkedMemories) > 0 {
+		return SelectMarkedMemories(m)
+	}
+	match, ok := CursorMatch(m)
+	if !ok {
+		return m, nil
+	}
+	return SelectMemories(m, []Memory{match.Memory}, match.Memory)
+}
+
+// SelectMemories selects `memory`, which runs the `used` memories, editing
+// it's placeholders if needed
+func SelectMemories(m Model, used []Memory, memory Memory) (Model, tea.Cmd) {
+	m.SelectedMemory = memory
+	m.SelectedMemories = used
 	if !NeedsEdit(m.SelectedMemory) {
-		return m, QuitWithOutput(m.SelectedMemory.Command)
+		executions, _ := RenderExecutions(m.SelectedMemory, []string{})
+		return SubmitExecutions(m, executions)
 	}
 	m = SetupEditTextInputs(m)
 	m.CurrentPage = PageEdit
@@ -189,43 +414,20 @@ func SelectCursorMemory(m Model) (newModel Model, quitCmd tea.Cmd) {
 // SubmitPlaceholderValueFromInput is called when an user submits the value
 // of the current placeholder value input
 func SubmitPlaceholderValueFromInput(m Model) (Model, tea.Cmd) {
-
-	// Find focusde input
-	focusedInputIndex := -1
-	for i, input := range m.EditTextInputs {
-		if input.Focused() {
-			focusedInputIndex = i
-			break
-		}
-	}
-
-	// If none default to the first input
-	if focusedInputIndex == -1 {
-		focusedInputIndex = 0
-	}
-
-	hasNextInput := len(m.EditTextInputs) >= (focusedInputIndex + 2)
-
 	// No next input, render and return
-	if !hasNextInput {
-		placeholderValues := make([]string, len(m.EditTextInputs))
-		for i, input := range m.EditTextInputs {
-			placeholderValues[i] = input.Value()
-		}
-		rendered := Render(m.SelectedMemory.Command, placeholderValues)
-		return m, QuitWithOutput(rendered)
+	if FocusedEditInput(m) >= len(m.EditTextInputs)-1 {
+		return SubmitEdit(m)
 	}
 
 	// Focus next input
-	m.EditTextInputs[focusedInputIndex].Blur()
-	return m, tea.Batch(m.EditTextInputs[focusedInputIndex+1].Focus())
+	return MoveEditFocus(m, 1)
 }
 
 // SetupEditTextInputs prepares the TextInputs for the Edit page
 func SetupEditTextInputs(m Model) Model {
-	mem := m.SelectedMemory
-	m.EditTextInputs = make([]textinput.Model, CountPlaceholders(mem.Command))
-	for i, placeholder := range GetPlaceholders(mem.Command) {
+	placeholders, _ := EditPlaceholders(m.SelectedMemory)
+	m.EditTextInputs = make([]textinput.Model, len(placeholders))
+	for i, placeholder := range placeholders {
 		m.EditTextInputs[i] = textinput.New()
 		m.EditTextInputs[i].Cursor.SetMode(cursor.CursorStatic)
 		m.EditTextInputs[i].Prompt = placeholder.Name + ": "
@@ -239,8 +441,16 @@ func SetupEditTextInputs(m Model) Model {
 // LoadMemories handle memories loaded
 func LoadMemories(m Model, mems []Memory) Model {
 	m.Memories = mems
-	m = m.UpdateMatches(m, true)
-	return m
+	return UpdateMatchesNow(m)
+}
+
+// UpdateMatchesNow synchronously recalculates the matches, discarding any
+// asynchronous search in progress.
+func UpdateMatchesNow(m Model) Model {
+	m = CancelSearch(m)
+	m.SearchInput = m.SearchTextInput.Value()
+	matches, _ := GetMatchesContext(context.Background(), m.Searcher, m.SearchOptions.Ranking, m.Memories, m.SearchInput)
+	return SetMatches(m, matches)
 }
 
 // Init implements tea.Model.
@@ -250,39 +460,98 @@ func (m Model) Init() tea.Cmd { return m.LoadMemories(m.AppOpts) }
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if key.Matches(msg, m.Keys.Quit) {
+			if m.CurrentPage == PageSteps {
+				return m, QuitSteps(m)
+			}
 			return m, tea.Quit
 		}
 		switch m.CurrentPage {
 		case PageSelect:
-			switch msg.Type {
-			case tea.KeyDown:
+			switch {
+			case key.Matches(msg, m.Keys.Down):
 				return IncreaseMatchCursor(m), nil
-			case tea.KeyUp:
+			case key.Matches(msg, m.Keys.Up):
 				return DecreaseMatchCursor(m), nil
-			case tea.KeyEnter:
+			case key.Matches(msg, m.Keys.PageDown):
+				return PageDownMatchCursor(m), nil
+			case key.Matches(msg, m.Keys.PageUp):
+				return PageUpMatchCursor(m), nil
+			case key.Matches(msg, m.Keys.Home):
+				return HomeMatchCursor(m), nil
+			case key.Matches(msg, m.Keys.End):
+				return EndMatchCursor(m), nil
+			case key.Matches(msg, m.Keys.Select):
 				return SelectCursorMemory(m)
+			case key.Matches(msg, m.Keys.CycleSearchMode):
+				return CycleSearchMode(m), nil
+			case key.Matches(msg, m.Keys.TogglePreview):
+				return TogglePreview(m), nil
+			case key.Matches(msg, m.Keys.ToggleShowAll):
+				return ToggleShowAll(m), nil
+			case key.Matches(msg, m.Keys.Mark):
+				return ToggleMark(m), nil
 			}
 		case PageEdit:
-			switch msg.Type {
-			case tea.KeyEnter:
+			switch {
+			case key.Matches(msg, m.Keys.Submit):
 				return SubmitPlaceholderValueFromInput(m)
+			case key.Matches(msg, m.Keys.SubmitAll):
+				return SubmitEdit(m)
+			case key.Matches(msg, m.Keys.NextInput):
+				return MoveEditFocus(m, 1)
+			case key.Matches(msg, m.Keys.PreviousInput):
+				return MoveEditFocus(m, -1)
+			case key.Matches(msg, m.Keys.Back):
+				return BackToSelect(m), nil
+			}
+		case PageReview:
+			switch {
+			case key.Matches(msg, m.Keys.Submit):
+				return ConfirmReview(m)
+			case key.Matches(msg, m.Keys.SaveMemory):
+				return ToggleReviewSave(m)
+			case key.Matches(msg, m.Keys.Back):
+				return BackFromReview(m), nil
+			}
+		case PageSteps:
+			switch {
+			case key.Matches(msg, m.Keys.Submit):
+				return RunCurrentStep(m)
+			case key.Matches(msg, m.Keys.NextInput):
+				return SkipCurrentStep(m), nil
+			case key.Matches(msg, m.Keys.PreviousInput):
+				return PreviousStep(m), nil
+			}
+		case PageConfirm:
+			switch {
+			case key.Matches(msg, m.Keys.Submit):
+				return SubmitConfirm(m)
+			case key.Matches(msg, m.Keys.Back):
+				return BackFromConfirm(m), nil
 			}
 		}
+	case tea.WindowSizeMsg:
+		return Resize(m, msg.Width, msg.Height), nil
 	case LoadedMemories:
 		return LoadMemories(m, msg), nil
+	case SearchDebounced:
+		return StartSearch(m, msg)
 	case SetMatched:
-		m.Matches = msg
-		return m, nil
+		return ReceiveMatches(m, msg), nil
+	case StepFinished:
+		return FinishStep(m, msg), nil
 	}
 
 	// Cmd to return
 	var cmd tea.Cmd
 
-	// Update the text input (since it might have changed)
+	// Update the text input (since it might have changed), and search again if so
 	if m.CurrentPage == PageSelect {
+		var searchCmd tea.Cmd
 		m.SearchTextInput, cmd = m.SearchTextInput.Update(msg)
+		m, searchCmd = ScheduleSearch(m)
+		cmd = tea.Batch(cmd, searchCmd)
 	}
 
 	// Update the Edit view text inputs
@@ -292,8 +561,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = tea.Batch(cmd, editTextInputsCmds)
 	}
 
-	// Update the matches to keep it in sync with the Memories/Input that may have changed
-	m = m.UpdateMatches(m, false)
+	// Update the Confirm view input
+	if m.CurrentPage == PageConfirm {
+		var confirmCmd tea.Cmd
+		m.ConfirmInput, confirmCmd = m.ConfirmInput.Update(msg)
+		cmd = tea.Batch(cmd, confirmCmd)
+	}
+
+	// Update the Review view inputs
+	if m.CurrentPage == PageReview {
+		var reviewCmd tea.Cmd
+		m, reviewCmd = UpdateReview(m, msg)
+		cmd = tea.Batch(cmd, reviewCmd)
+	}
 
 	return m, cmd
 }
@@ -315,9 +595,18 @@ func (m *Model) UpdateEditTextInputs(msg tea.Msg) ([]textinput.Model, tea.Cmd) {
 // View implements tea.Model.
 func (m Model) View() string {
 	if m.CurrentPage == PageEdit {
-		return ViewCommandEdit(m)
+		return ViewCommandEdit(m) + ViewHelp(m)
 	}
-	return ViewCommandSelection(m)
+	if m.CurrentPage == PageReview {
+		return ViewCommandReview(m) + ViewHelp(m)
+	}
+	if m.CurrentPage == PageConfirm {
+		return ViewCommandConfirm(m) + ViewHelp(m)
+	}
+	if m.CurrentPage == PageSteps {
+		return ViewSteps(m) + ViewHelp(m)
+	}
+	return ViewCommandSelection(m) + ViewHelp(m)
 }
 
 func (m Model) GetPlaceholderValues() []string {
@@ -343,24 +632,57 @@ func getOutputFile() *os.File {
 	return os.Stderr
 }
 
-// NeedsEdit returns True if a memory needs to be edited before returning
+// NeedsEdit returns True if a memory needs to be edited before returning.
+// Memories with invalid placeholders also need edit, so the user sees the error.
 func NeedsEdit(m Memory) bool {
-	return !(CountPlaceholders(m.Command) == 0)
+	placeholders, err := EditPlaceholders(m)
+	return err != nil || len(placeholders) > 0
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == RenderSubcommand {
+		renderOpts, err := ParseRenderOptions(os.Args[2:], env.ToMap(os.Environ()))
+		if err != nil {
+			exitWithErr("failed to parse CLI args", err)
+		}
+		renderOpts.Config, err = LoadConfigFile(renderOpts.ConfigFile)
+		if err != nil {
+			exitWithErr("failed to load config", err)
+		}
+		terminal := getOutputFile()
+		defer terminal.Close()
+		sink, err := NewOutputSink(renderOpts.Output, terminal)
+		if err != nil {
+			exitWithErr("failed to open output", err)
+		}
+		if err := RunRender(renderOpts, sink); err != nil {
+			exitWithErr("failed to render", err)
+		}
+		return
+	}
+
 	appOpts, err := ParseAppOptions(os.Args[1:], env.ToMap(os.Environ()))
 	if err != nil {
 		exitWithErr("failed to parse CLI args", err)
 	}
+	appOpts.Config, err = LoadConfigFile(appOpts.ConfigFile)
+	if err != nil {
+		exitWithErr("failed to load config", err)
+	}
 
 	model := InitialModel(appOpts)
 
 	outputFile := getOutputFile()
 	defer outputFile.Close()
 
+	sink, err := NewOutputSink(appOpts.Output, outputFile)
+	if err != nil {
+		exitWithErr("failed to open output", err)
+	}
+
 	p := tea.NewProgram(model, tea.WithOutput(outputFile))
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	if err != nil {
 		exitWithErr("exited with error: %v", err)
 	}
 
@@ -369,6 +691,24 @@ func main() {
 	}
 
 	if QuitOutput != "" {
-		fmt.Print(QuitOutput)
+		if RecordsUsage(appOpts) {
+			for _, memory := range finalModel.(Model).SelectedMemories {
+				if err := RecordUsage(appOpts.UsageFile, memory); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to record usage: %s\n", err)
+				}
+			}
+		}
+		if finalModel.(Model).NewMemory.Command != "" {
+			if err := AppendMemory(appOpts.MemoriesFile, finalModel.(Model).NewMemory); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to save memory: %s\n", err)
+			}
+		}
+		if appOpts.Exec {
+			outputFile.Close()
+			ExecAndExit(QuitExecution, env.ToMap(os.Environ()))
+		}
+		if err := sink.Output(QuitOutput); err != nil {
+			exitWithErr("failed to output command", err)
+		}
 	}
 }
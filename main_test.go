@@ -17,6 +17,7 @@ func cleanup() {
 	// Cleanup the global QuitErr
 	sazed.QuitErr = nil
 	sazed.QuitOutput = ""
+	sazed.QuitExecution = sazed.Execution{}
 }
 func memory1() sazed.Memory {
 	return sazed.Memory{Command: "cmd1", Description: "Memory 1"}
@@ -38,16 +39,30 @@ func memory5() sazed.Memory {
 	return sazed.Memory{Command: "echo {{value1}} {{value2}} end", Description: "not bar"}
 }
 
-func newTestModel() sazed.Model {
-	return sazed.InitialModel(sazed.AppOptions{
+// newTestModel returns a model with the default app options, changed by
+// `overrides`
+func newTestModel(overrides ...func(*sazed.AppOptions)) sazed.Model {
+	opts := sazed.AppOptions{
 		CommandPrintLength: sazed.DefaultCommandPrintLength,
-	})
+		CommandWeight:      1,
+		DescriptionWeight:  1,
+		Separator:          sazed.JoinSeparatorAnd,
+	}
+	for _, override := range overrides {
+		override(&opts)
+	}
+	return sazed.InitialModel(opts)
 }
 
 func update(m sazed.Model, msg tea.Msg) sazed.Model {
 	return batchUpdate(m, func() tea.Msg { return msg })
 }
 
+// typeText types `text` in the focused input
+func typeText(m sazed.Model, text string) sazed.Model {
+	return update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+}
+
 func batchUpdate(m sazed.Model, cmd tea.Cmd) sazed.Model {
 	if cmd == nil {
 		return m
@@ -56,6 +71,12 @@ func batchUpdate(m sazed.Model, cmd tea.Cmd) sazed.Model {
 	if _, ok := msg.(tea.QuitMsg); ok {
 		return m
 	}
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			m = batchUpdate(m, cmd)
+		}
+		return m
+	}
 	m2, cmd := m.Update(msg)
 	return batchUpdate(m2.(sazed.Model), cmd)
 }
@@ -72,6 +93,116 @@ func Test__ParseAppOptions(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Contains(t, opts.MemoriesFile, ".config/sazed/memories.yaml")
 		assert.Equal(t, opts.CommandPrintLength, sazed.DefaultCommandPrintLength)
+		assert.Equal(t, sazed.SearchModeFuzzy, opts.SearchMode)
+		assert.True(t, opts.SmartCase)
+		assert.True(t, opts.FoldAccents)
+		assert.True(t, opts.Review)
+	})
+
+	t.Run("normalisation from env and args", func(t *testing.T) {
+		env := map[string]string{"SAZED_SMART_CASE": "false"}
+		args := []string{"--fold-accents=false"}
+
+		opts, err := sazed.ParseAppOptions(args, env)
+
+		assert.Nil(t, err)
+		assert.False(t, opts.SmartCase)
+		assert.False(t, opts.FoldAccents)
+	})
+
+	t.Run("review can be skipped", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{"--review=false"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.False(t, opts.Review)
+
+		opts, err = sazed.ParseAppOptions([]string{}, map[string]string{"SAZED_REVIEW": "false"})
+		assert.Nil(t, err)
+		assert.False(t, opts.Review)
+	})
+
+	t.Run("search mode from env and args", func(t *testing.T) {
+		env := map[string]string{"SAZED_SEARCH_MODE": "regex"}
+
+		opts, err := sazed.ParseAppOptions([]string{}, env)
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.SearchModeRegex, opts.SearchMode)
+
+		opts, err = sazed.ParseAppOptions([]string{"--search-mode=prefix"}, env)
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.SearchModePrefix, opts.SearchMode)
+	})
+
+	t.Run("ranking options", func(t *testing.T) {
+		env := map[string]string{"SAZED_TIE_BREAK": "usage", "SAZED_COMMAND_WEIGHT": "2"}
+		args := []string{"--explain-scores", "--description-weight=0.5"}
+
+		opts, err := sazed.ParseAppOptions(args, env)
+
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.TieBreakUsage, opts.TieBreak)
+		assert.Equal(t, 2.0, opts.CommandWeight)
+		assert.Equal(t, 0.5, opts.DescriptionWeight)
+		assert.True(t, opts.ExplainScores)
+	})
+
+	t.Run("defaults each weight on its own", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{"--command-weight=2"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, opts.CommandWeight)
+		assert.Equal(t, 1.0, opts.DescriptionWeight)
+
+		opts, err = sazed.ParseAppOptions([]string{}, map[string]string{"SAZED_DESCRIPTION_WEIGHT": "0"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1.0, opts.CommandWeight)
+		assert.Equal(t, 0.0, opts.DescriptionWeight)
+
+		_, err = sazed.ParseAppOptions([]string{"--command-weight=0", "--description-weight=0"}, map[string]string{})
+		assert.ErrorContains(t, err, "weights can not both be zero")
+	})
+
+	t.Run("errors if unknown tie break", func(t *testing.T) {
+		_, err := sazed.ParseAppOptions([]string{"--tie-break=foo"}, map[string]string{})
+		assert.ErrorContains(t, err, `unknown tie break "foo"`)
+	})
+
+	t.Run("separator", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.JoinSeparatorAnd, opts.Separator)
+
+		opts, err = sazed.ParseAppOptions([]string{"--separator=newline"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.JoinSeparatorNewline, opts.Separator)
+
+		_, err = sazed.ParseAppOptions([]string{"--separator=|"}, map[string]string{})
+		assert.ErrorContains(t, err, `unknown separator "|"`)
+	})
+
+	t.Run("output", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, "stdout", opts.Output)
+
+		opts, err = sazed.ParseAppOptions([]string{}, map[string]string{"SAZED_OUTPUT": "fd:3"})
+		assert.Nil(t, err)
+		assert.Equal(t, "fd:3", opts.Output)
+
+		_, err = sazed.ParseAppOptions([]string{"--output=clipboard"}, map[string]string{})
+		assert.ErrorContains(t, err, `unknown output "clipboard"`)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{"--exec"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.True(t, opts.Exec)
+
+		_, err = sazed.ParseAppOptions([]string{"--exec", "--output=osc52"}, map[string]string{})
+		assert.ErrorContains(t, err, "--exec can not be used with --output")
+	})
+
+	t.Run("errors if unknown search mode", func(t *testing.T) {
+		_, err := sazed.ParseAppOptions([]string{"--search-mode=foo"}, map[string]string{})
+		assert.ErrorContains(t, err, `unknown search mode "foo"`)
 	})
 
 	t.Run("args have preference over env", func(t *testing.T) {
@@ -156,10 +287,42 @@ func Test__LoadMemoriesFromYaml(t *testing.T) {
 		memories, err := sazed.LoadMemoriesFromYaml(reader)
 		assert.Nil(t, err)
 		assert.Equal(t, []sazed.Memory{
-			{Command: "foo", Description: "bar"},
-			{Command: "bar", Description: "baz"},
+			sazed.CompileMemory(sazed.Memory{Command: "foo", Description: "bar"}),
+			sazed.CompileMemory(sazed.Memory{Command: "bar", Description: "baz"}),
 		}, memories)
 	})
+	t.Run("loads confirm", func(t *testing.T) {
+		reader := strings.NewReader("- {command: reboot, confirm: true}\n")
+		memories, err := sazed.LoadMemoriesFromYaml(reader)
+		assert.Nil(t, err)
+		assert.True(t, memories[0].Confirm)
+	})
+	t.Run("loads cwd and env", func(t *testing.T) {
+		reader := strings.NewReader("- {command: ls, cwd: /tmp, env: {FOO: bar}}\n")
+		memories, err := sazed.LoadMemoriesFromYaml(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, "/tmp", memories[0].Cwd)
+		assert.Equal(t, map[string]string{"FOO": "bar"}, memories[0].Env)
+	})
+	t.Run("loads workflow steps", func(t *testing.T) {
+		reader := strings.NewReader("- {description: release, steps: [{command: make test, description: test}, {command: make release}]}\n")
+		memories, err := sazed.LoadMemoriesFromYaml(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, []sazed.Step{{Command: "make test", Description: "test"}, {Command: "make release"}}, memories[0].Steps)
+		assert.Equal(t, "make test && make release", memories[0].Command)
+	})
+	t.Run("errors on invalid env var names", func(t *testing.T) {
+		reader := strings.NewReader("- {command: ls, env: {FOO-BAR: baz}}\n")
+		_, err := sazed.LoadMemoriesFromYaml(reader)
+		assert.ErrorContains(t, err, `invalid env var name "FOO-BAR"`)
+	})
+	t.Run("compiles memories templates", func(t *testing.T) {
+		reader := strings.NewReader("- {command: \"echo {{foo}}\", description: \"bar\"}\n")
+		memories, err := sazed.LoadMemoriesFromYaml(reader)
+		assert.Nil(t, err)
+		assert.NotNil(t, memories[0].Template)
+		assert.Equal(t, 1, memories[0].Template.CountPlaceholders())
+	})
 }
 
 func Test__InitLoadMemories(t *testing.T) {
@@ -173,7 +336,7 @@ func Test__InitLoadMemories(t *testing.T) {
 		msg := sazed.InitLoadMemories(appOpts)()
 
 		assert.Equal(t, msg, sazed.LoadedMemories([]sazed.Memory{
-			{Command: "foo", Description: "bar"},
+			sazed.CompileMemory(sazed.Memory{Command: "foo", Description: "bar", Source: memoriesFile}),
 		}))
 	})
 	t.Run("report error if loaded from file", func(t *testing.T) {
@@ -198,6 +361,22 @@ func Test__InitLoadMemories(t *testing.T) {
 		assert.Equal(t, tea.QuitMsg{}, msg)
 		assert.ErrorContains(t, sazed.QuitErr, "unmarshal error")
 	})
+	t.Run("only reads the usage file with the usage tie break", func(t *testing.T) {
+		defer cleanup()
+		memoriesFile := path.Join(t.TempDir(), "foo")
+		_ = os.WriteFile(memoriesFile, []byte("- {command: foo, description: bar}"), 0644)
+		usageFile := path.Join(t.TempDir(), "usage")
+		_ = os.WriteFile(usageFile, []byte("INV{A}LID{YAML"), 0644)
+		appOpts := sazed.AppOptions{MemoriesFile: memoriesFile, UsageFile: usageFile}
+
+		msg := sazed.InitLoadMemories(appOpts)()
+		assert.IsType(t, sazed.LoadedMemories{}, msg)
+
+		appOpts.TieBreak = sazed.TieBreakUsage
+		msg = sazed.InitLoadMemories(appOpts)()
+		assert.Equal(t, tea.QuitMsg{}, msg)
+		assert.ErrorContains(t, sazed.QuitErr, "failed to parse usage file")
+	})
 }
 
 func Test__LoadMemories(t *testing.T) {
@@ -221,7 +400,7 @@ func Test__Update(t *testing.T) {
 		// User writes and matches first one
 		model.SearchTextInput.SetValue(memory1().Description)
 		// Update matches with user input
-		model = model.UpdateMatches(model, true)
+		model = sazed.UpdateMatchesNow(model)
 		// User hits enter
 		teaModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
 
@@ -239,7 +418,7 @@ func Test__Update(t *testing.T) {
 
 		// User inputs Bar and selects second memory
 		model.SearchTextInput.SetValue("bar")
-		model = model.UpdateMatches(model, true)
+		model = sazed.UpdateMatchesNow(model)
 
 		// User hits enter
 		model, cmd := sazed.SelectCursorMemory(model)
@@ -248,6 +427,52 @@ func Test__Update(t *testing.T) {
 		assert.Equal(t, tea.QuitMsg{}, cmd())
 		assert.Equal(t, memory2().Command, sazed.QuitOutput)
 	})
+	t.Run("typing q does not quit", func(t *testing.T) {
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{memory1()}))
+
+		teaModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+		assert.Equal(t, "q", teaModel.(sazed.Model).SearchTextInput.Value())
+	})
+	t.Run("ctrl+c quits", func(t *testing.T) {
+		_, cmd := newTestModel().Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+		assert.Equal(t, tea.QuitMsg{}, cmd())
+	})
+	t.Run("moves with configured keys", func(t *testing.T) {
+		m := newTestModel(func(opts *sazed.AppOptions) {
+			opts.Config = sazed.Config{Keys: sazed.KeysConfig{Style: sazed.KeyStyleEmacs}}
+		})
+		m = update(m, sazed.LoadedMemories([]sazed.Memory{memory1(), memory2()}))
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlN})
+		assert.Equal(t, 1, m.MatchCursor)
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlP})
+		assert.Equal(t, 0, m.MatchCursor)
+	})
+	t.Run("enter with no matches does nothing", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{memory1()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+		assert.Len(t, m.Matches, 0)
+
+		teaModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Nil(t, cmd)
+		assert.Equal(t, sazed.PageSelect, teaModel.(sazed.Model).CurrentPage)
+	})
+	t.Run("keeps the selected memory while typing", func(t *testing.T) {
+		memories := []sazed.Memory{memory1(), memory2(), memory3()}
+		m := update(newTestModel(), sazed.LoadedMemories(memories))
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, memory3(), m.Matches[m.MatchCursor].Memory)
+
+		// memory3 goes from third to second row
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("foo")})
+
+		assert.Equal(t, 1, m.MatchCursor)
+		assert.Equal(t, memory3(), m.Matches[m.MatchCursor].Memory)
+	})
 	t.Run("selects memory from user input with placehoder", func(t *testing.T) {
 		// Load memories
 		memories := []sazed.Memory{memory4()}
@@ -269,7 +494,7 @@ func Test__View(t *testing.T) {
 
 		rendered := strings.Split(model.View(), "\n")
 
-		assert.Equal(t, "Please select a command", rendered[0])
+		assert.Equal(t, "Please select a command (mode: fuzzy, ctrl+t to change)", rendered[0])
 		assert.Contains(t, rendered[3], "cmd1")
 		assert.Contains(t, rendered[4], "Memory 1")
 	})
@@ -316,6 +541,19 @@ func Test__View(t *testing.T) {
 		// First command should not be there
 		assert.Len(t, rendered, 8)
 	})
+	t.Run("explains scores", func(t *testing.T) {
+		model := newTestModel()
+		model.AppOpts.ExplainScores = true
+		model = update(model, sazed.LoadedMemories([]sazed.Memory{memory1(), memory2()}))
+		model = update(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bar")})
+
+		rendered := strings.Split(model.View(), "\n")
+		assert.Equal(t, "      |Bar [score 30 = cmd 0*1 + desc 30*1, #2]", rendered[4])
+
+		model = update(model, tea.WindowSizeMsg{Width: 20, Height: 20})
+		rendered = strings.Split(sazed.ViewMatches(model, model.Width), "\n")
+		assert.Equal(t, "      |Bar [score 30", rendered[1])
+	})
 	t.Run("moves cursor around", func(t *testing.T) {
 		// Load memories
 		memories := sazed.LoadedMemories([]sazed.Memory{memory1(), memory2(), memory3()})
@@ -338,6 +576,25 @@ func Test__View(t *testing.T) {
 		assert.Contains(t, rendered[3], ">> cmd1")
 		assert.Contains(t, rendered[5], "   foo")
 	})
+	t.Run("cycles search mode", func(t *testing.T) {
+		memories := sazed.LoadedMemories([]sazed.Memory{memory1(), memory2(), memory3()})
+		model := update(newTestModel(), memories)
+		model = update(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("fo")})
+		assert.Len(t, model.Matches, 2)
+
+		model = update(model, tea.KeyMsg{Type: tea.KeyCtrlT})
+		assert.Equal(t, sazed.SearchModeSubstring, model.SearchMode)
+		model = update(model, tea.KeyMsg{Type: tea.KeyCtrlT})
+		assert.Equal(t, sazed.SearchModePrefix, model.SearchMode)
+		model = update(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+		assert.Len(t, model.Matches, 2)
+		model.SearchTextInput.SetValue("")
+		model = update(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("oo")})
+		assert.Len(t, model.Matches, 0)
+
+		rendered := strings.Split(model.View(), "\n")
+		assert.Equal(t, "Please select a command (mode: prefix, ctrl+t to change)", rendered[0])
+	})
 	t.Run("renders edit view", func(t *testing.T) {
 		// Prepare model with view and page
 		var cmd tea.Cmd
@@ -378,73 +635,29 @@ func (f *FakeFuzzy) GetMatches(memories []sazed.Memory, input string) []sazed.Ma
 	return f.mockResult
 }
 
-func Test__UpdateMatches(t *testing.T) {
-	t.Cleanup(cleanup)
-
-	t.Run("skip if no memories and no input", func(t *testing.T) {
-		updateMatches := sazed.UpdateMatches(&FakeFuzzy{[]sazed.Match{}})
+func Test__UpdateMatchesNow(t *testing.T) {
+	t.Run("matches with the searcher", func(t *testing.T) {
+		newMatches := []sazed.Match{{Memory: memory1(), Score: 30, CommandScore: 30}}
 		model := newTestModel()
-		model.SearchTextInput.SetValue("")
-		model.Memories = []sazed.Memory{}
-		model = updateMatches(model, false)
-		assert.Equal(t, []sazed.Match{}, model.Matches)
-	})
-
-	t.Run("don't change matches if unchanged input", func(t *testing.T) {
-		oldMatches := []sazed.Match{{Score: 20}}
-		newMatches := []sazed.Match{{Score: 30}}
-		updateMatches := sazed.UpdateMatches(&FakeFuzzy{newMatches})
-		model := newTestModel()
-		model.Matches = oldMatches
-
-		// Fist call with "foo", ignore results
-		model.SearchTextInput.SetValue("foo")
-		_ = updateMatches(model, false)
-
-		// Second call with "foo", save results
-		model.SearchTextInput.SetValue("foo")
-		model = updateMatches(model, false)
-
-		// Old matches are still there (cache)
-		assert.Equal(t, oldMatches, model.Matches)
-	})
-
-	t.Run("return update result if input changed", func(t *testing.T) {
-		oldMatches := []sazed.Match{{Score: 20}}
-		newMatches := []sazed.Match{{Score: 30}}
-		updateMatches := sazed.UpdateMatches(&FakeFuzzy{newMatches})
-		model := newTestModel()
-		model.Matches = oldMatches
-
-		// Fist call with "foo", ignore results
+		model.Memories = []sazed.Memory{memory1()}
+		model.Searcher = &FakeFuzzy{newMatches}
 		model.SearchTextInput.SetValue("foo")
-		_ = updateMatches(model, false)
 
-		// Second call wth "bar"
-		model.SearchTextInput.SetValue("bar")
-		model = updateMatches(model, false)
+		model = sazed.UpdateMatchesNow(model)
 
-		// New matches are set
 		assert.Equal(t, newMatches, model.Matches)
+		assert.Equal(t, "foo", model.SearchInput)
 	})
+	t.Run("discards the search in progress", func(t *testing.T) {
+		model := sazed.LoadMemories(newTestModel(), []sazed.Memory{memory1(), memory2()})
+		model.SearchTextInput.SetValue("bar")
+		model, _ = sazed.ScheduleSearch(model)
+		staleSeq := model.SearchSeq
 
-	t.Run("updates matches if cache clean", func(t *testing.T) {
-		oldMatches := []sazed.Match{{Score: 20}}
-		newMatches := []sazed.Match{{Score: 30}}
-		updateMatches := sazed.UpdateMatches(&FakeFuzzy{newMatches})
-		model := newTestModel()
-		model.Matches = oldMatches
-
-		// Fist call with "foo", ignore results
-		model.SearchTextInput.SetValue("foo")
-		_ = updateMatches(model, false)
-
-		// Second call with "foo", save results
-		model.SearchTextInput.SetValue("foo")
-		model = updateMatches(model, true)
+		model = sazed.UpdateMatchesNow(model)
 
-		// New matches are there since we cleaned cache
-		assert.Equal(t, newMatches, model.Matches)
+		assert.NotEqual(t, staleSeq, model.SearchSeq)
+		assert.Len(t, model.Matches, 1)
 	})
 }
 
@@ -503,6 +716,19 @@ func Test__SubmitPlaceholderValueFromInput(t *testing.T) {
 		assert.False(t, m.EditTextInputs[0].Focused())
 		assert.True(t, m.EditTextInputs[1].Focused())
 	})
+	t.Run("does not quit if command has invalid placeholders", func(t *testing.T) {
+		defer cleanup()
+		m := newTestModel()
+		m = sazed.LoadMemories(m, []sazed.Memory{{Command: "echo {{foo}} {{bar"}})
+		m, cmd := sazed.SelectCursorMemory(m)
+		assert.Nil(t, cmd)
+		m.EditTextInputs[0].SetValue("foo")
+
+		_, cmd = sazed.SubmitPlaceholderValueFromInput(m)
+
+		assert.Nil(t, cmd)
+		assert.Equal(t, "", sazed.QuitOutput)
+	})
 }
 
 func Test__SetupEditTextInputs(t *testing.T) {
@@ -533,7 +759,7 @@ func Test__UpdateEditTextInputs(t *testing.T) {
 	t.Run("sets value if input", func(t *testing.T) {
 		m := newTestModel()
 		m = sazed.LoadMemories(m, []sazed.Memory{memory5()})
-		m = m.UpdateMatches(m, true)
+		m = sazed.UpdateMatchesNow(m)
 		m, _ = sazed.SelectCursorMemory(m)
 		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f', 'o', 'o'}}
 		var cmd tea.Cmd
@@ -549,4 +775,5 @@ func Test__NeedsEdit(t *testing.T) {
 	assert.False(t, sazed.NeedsEdit(memory3()))
 	assert.True(t, sazed.NeedsEdit(memory4()))
 	assert.True(t, sazed.NeedsEdit(memory5()))
+	assert.True(t, sazed.NeedsEdit(sazed.Memory{Command: "echo {{foo"}))
 }
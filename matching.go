@@ -0,0 +1,103 @@
+// This file contains the asynchronous matching: searches run in a
+// background tea.Cmd after the user stops typing, and stale searches are
+// cancelled.
+package main
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultSearchDebounce is how long to wait after a keystroke before searching
+const DefaultSearchDebounce = 30 * time.Millisecond
+
+// SearchChunkSize is how many memories are matched between cancellation checks
+const SearchChunkSize = 1000
+
+// SearchDebounced is sent when the user stopped typing for the debounce time
+type SearchDebounced struct {
+	Seq int
+}
+
+// SetMatched is sent with the results of a search
+type SetMatched struct {
+	Seq     int
+	Matches []Match
+}
+
+// GetMatchesContext matches `memories` in chunks, so that it can stop early
+// if `ctx` is cancelled. The searcher ranks each chunk with `ranking`, so the
+// chunks are only merged.
+func GetMatchesContext(ctx context.Context, searcher IFuzzy, ranking Ranking, memories []Memory, input string) ([]Match, error) {
+	chunks := [][]Match{}
+	for offset := 0; offset < len(memories); offset += SearchChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(offset+SearchChunkSize, len(memories))
+		matches := searcher.GetMatches(memories[offset:end], input)
+		for i := range matches {
+			matches[i].Index += offset
+		}
+		chunks = append(chunks, matches)
+	}
+	return ranking.Merge(chunks), nil
+}
+
+// CancelSearch cancels any running search and invalidates pending results
+func CancelSearch(m Model) Model {
+	m.SearchSeq++
+	if m.CancelSearchFn != nil {
+		m.CancelSearchFn()
+		m.CancelSearchFn = nil
+	}
+	return m
+}
+
+// ScheduleSearch schedules a search if the user input changed since the last
+// search. The search only starts after the debounce time.
+func ScheduleSearch(m Model) (Model, tea.Cmd) {
+	input := m.SearchTextInput.Value()
+	if input == m.SearchInput {
+		return m, nil
+	}
+	m = CancelSearch(m)
+	m.SearchInput = input
+	seq := m.SearchSeq
+	return m, tea.Tick(m.SearchDebounce, func(time.Time) tea.Msg {
+		return SearchDebounced{Seq: seq}
+	})
+}
+
+// StartSearch starts the search for a debounced input, unless a newer input
+// already replaced it.
+func StartSearch(m Model, msg SearchDebounced) (Model, tea.Cmd) {
+	if msg.Seq != m.SearchSeq {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.CancelSearchFn = cancel
+	searcher, ranking, memories, input, seq := m.Searcher, m.SearchOptions.Ranking, m.Memories, m.SearchInput, m.SearchSeq
+	return m, func() tea.Msg {
+		matches, err := GetMatchesContext(ctx, searcher, ranking, memories, input)
+		if err != nil {
+			return nil
+		}
+		return SetMatched{Seq: seq, Matches: matches}
+	}
+}
+
+// ReceiveMatches sets the matches of a search, ignoring stale results
+func ReceiveMatches(m Model, msg SetMatched) Model {
+	if msg.Seq != m.SearchSeq {
+		return m
+	}
+	m = SetMatches(m, msg.Matches)
+	if m.CancelSearchFn != nil {
+		m.CancelSearchFn()
+		m.CancelSearchFn = nil
+	}
+	return m
+}
@@ -0,0 +1,134 @@
+package main_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func manyMemories(n int) []sazed.Memory {
+	memories := make([]sazed.Memory, n)
+	for i := range memories {
+		memories[i] = sazed.Memory{
+			Command:     fmt.Sprintf("docker run --name container-%d image:%d", i, i%97),
+			Description: fmt.Sprintf("Runs container number %d from the imported history", i),
+		}
+	}
+	return memories
+}
+
+func Test__GetMatchesContext(t *testing.T) {
+	t.Run("matches all chunks and ranks them together", func(t *testing.T) {
+		memories := manyMemories(2*sazed.SearchChunkSize + 10)
+		memories[2*sazed.SearchChunkSize+5].Command = "xyz"
+
+		matches, err := sazed.GetMatchesContext(context.Background(), sazed.NewFuzzy(), sazed.DefaultRanking(), memories, "xyz")
+
+		assert.Nil(t, err)
+		assert.Len(t, matches, 1)
+		assert.Equal(t, 2*sazed.SearchChunkSize+5, matches[0].Index)
+	})
+	t.Run("merges the ranked chunks", func(t *testing.T) {
+		memories := manyMemories(2*sazed.SearchChunkSize + 10)
+		memories[5].Command = "xyz abc"
+		memories[sazed.SearchChunkSize+5].Command = "xyz"
+		memories[2*sazed.SearchChunkSize+5].Command = "xyz"
+		ranking := sazed.DefaultRanking()
+		ranking.TieBreak = sazed.TieBreakLength
+		fuzzy := sazed.NewFuzzy()
+		fuzzy.Ranking = ranking
+
+		matches, err := sazed.GetMatchesContext(context.Background(), fuzzy, ranking, memories, "xyz")
+
+		assert.Nil(t, err)
+		indexes := []int{}
+		for _, match := range matches {
+			indexes = append(indexes, match.Index)
+		}
+		assert.Equal(t, []int{sazed.SearchChunkSize + 5, 2*sazed.SearchChunkSize + 5, 5}, indexes)
+	})
+	t.Run("stops if cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := sazed.GetMatchesContext(ctx, sazed.NewFuzzy(), sazed.DefaultRanking(), manyMemories(10), "foo")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func Test__AsyncSearch(t *testing.T) {
+	t.Run("does not schedule if input unchanged", func(t *testing.T) {
+		m := newTestModel()
+		m, cmd := sazed.ScheduleSearch(m)
+		assert.Nil(t, cmd)
+		assert.Equal(t, 0, m.SearchSeq)
+	})
+	t.Run("keystroke does not match synchronously", func(t *testing.T) {
+		m := sazed.LoadMemories(newTestModel(), []sazed.Memory{memory1(), memory2()})
+
+		teaModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bar")})
+		m = teaModel.(sazed.Model)
+
+		assert.NotNil(t, cmd)
+		assert.Len(t, m.Matches, 2)
+		assert.Equal(t, "bar", m.SearchInput)
+	})
+	t.Run("ignores stale searches", func(t *testing.T) {
+		m := sazed.LoadMemories(newTestModel(), []sazed.Memory{memory1(), memory2()})
+		m.SearchTextInput.SetValue("b")
+		m, _ = sazed.ScheduleSearch(m)
+		staleSeq := m.SearchSeq
+		m.SearchTextInput.SetValue("ba")
+		m, _ = sazed.ScheduleSearch(m)
+
+		m, cmd := sazed.StartSearch(m, sazed.SearchDebounced{Seq: staleSeq})
+		assert.Nil(t, cmd)
+
+		m = sazed.ReceiveMatches(m, sazed.SetMatched{Seq: staleSeq, Matches: []sazed.Match{}})
+		assert.Len(t, m.Matches, 2)
+	})
+	t.Run("sets results of latest search", func(t *testing.T) {
+		m := sazed.LoadMemories(newTestModel(), []sazed.Memory{memory1(), memory2()})
+		m.SearchTextInput.SetValue("bar")
+		m, cmd := sazed.ScheduleSearch(m)
+
+		m = batchUpdate(m, cmd)
+
+		assert.Len(t, m.Matches, 1)
+		assert.Equal(t, memory2(), m.Matches[0].Memory)
+	})
+}
+
+// BenchmarkKeystroke100k measures how long the UI is blocked by a keystroke
+// with 100k memories. The keystroke only schedules the debounced search, so
+// this should stay well under a millisecond (see
+// BenchmarkGetMatchesContext100k for the search itself).
+func BenchmarkKeystroke100k(b *testing.B) {
+	m := newTestModel()
+	m.Memories = manyMemories(100_000)
+	keys := []rune("container 42")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%len(keys) == 0 {
+			m.SearchTextInput.SetValue("")
+		}
+		key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{keys[i%len(keys)]}}
+		teaModel, _ := m.Update(key)
+		m = teaModel.(sazed.Model)
+	}
+}
+
+// BenchmarkGetMatchesContext100k measures a full background search with 100k
+// memories.
+func BenchmarkGetMatchesContext100k(b *testing.B) {
+	memories := manyMemories(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = sazed.GetMatchesContext(context.Background(), sazed.NewFuzzy(), sazed.DefaultRanking(), memories, "container 42")
+	}
+}
@@ -0,0 +1,96 @@
+// This file contains the multi-select, which outputs several memories joined
+// in a single command.
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// JoinSeparator is how the commands of marked memories are joined
+type JoinSeparator string
+
+// JoinSeparatorAnd runs each command only if the previous one succeeded
+const JoinSeparatorAnd JoinSeparator = "&&"
+
+// JoinSeparatorSemicolon runs all commands
+const JoinSeparatorSemicolon JoinSeparator = ";"
+
+// JoinSeparatorNewline puts each command in a line
+const JoinSeparatorNewline JoinSeparator = "newline"
+
+// JoinSeparators are all join separators
+var JoinSeparators = []JoinSeparator{JoinSeparatorAnd, JoinSeparatorSemicolon, JoinSeparatorNewline}
+
+// ParseJoinSeparator returns the JoinSeparator named `s`
+func ParseJoinSeparator(s string) (JoinSeparator, error) {
+	for _, separator := range JoinSeparators {
+		if string(separator) == s {
+			return separator, nil
+		}
+	}
+	return "", fmt.Errorf("unknown separator %q", s)
+}
+
+// Join joins `commands` with the separator
+func (s JoinSeparator) Join(commands []string) string {
+	switch s {
+	case JoinSeparatorSemicolon:
+		return strings.Join(commands, "; ")
+	case JoinSeparatorNewline:
+		return strings.Join(commands, "\n")
+	default:
+		return strings.Join(commands, " && ")
+	}
+}
+
+// ToggleMark marks or unmarks the match under the cursor. Marks are kept by
+// memory, so they survive changes in the query.
+func ToggleMark(m Model) Model {
+	match, ok := CursorMatch(m)
+	if !ok {
+		return m
+	}
+	if i := slices.Index(m.MarkedMemories, match.Index); i != -1 {
+		m.MarkedMemories = slices.Delete(slices.Clone(m.MarkedMemories), i, i+1)
+		return m
+	}
+	m.MarkedMemories = append(slices.Clone(m.MarkedMemories), match.Index)
+	return m
+}
+
+// IsMarked returns whether the memory of `match` is marked
+func IsMarked(m Model, match Match) bool {
+	return slices.Contains(m.MarkedMemories, match.Index)
+}
+
+// CombineMemories returns a memory running all `memories`, in order, with
+// the commands joined by `separator`
+func CombineMemories(memories []Memory, separator JoinSeparator) Memory {
+	commands := make([]string, len(memories))
+	descriptions := make([]string, len(memories))
+	confirm := false
+	for i, memory := range memories {
+		commands[i] = UnrenderedExecution(memory).ShellCommand()
+		descriptions[i] = memory.Description
+		confirm = confirm || memory.Confirm
+	}
+	return CompileMemory(Memory{
+		Command:     separator.Join(commands),
+		Description: strings.Join(descriptions, "; "),
+		Confirm:     confirm,
+	})
+}
+
+// SelectMarkedMemories selects the marked memories, in the order they were
+// marked, as a single combined memory
+func SelectMarkedMemories(m Model) (Model, tea.Cmd) {
+	memories := make([]Memory, len(m.MarkedMemories))
+	for i, index := range m.MarkedMemories {
+		memories[i] = m.Memories[index]
+	}
+	return SelectMemories(m, memories, CombineMemories(memories, m.AppOpts.Separator))
+}
@@ -0,0 +1,100 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestJoinSeparator(t *testing.T) {
+	commands := []string{"foo", "bar"}
+	assert.Equal(t, "foo && bar", sazed.JoinSeparatorAnd.Join(commands))
+	assert.Equal(t, "foo; bar", sazed.JoinSeparatorSemicolon.Join(commands))
+	assert.Equal(t, "foo\nbar", sazed.JoinSeparatorNewline.Join(commands))
+
+	separator, err := sazed.ParseJoinSeparator(";")
+	assert.Nil(t, err)
+	assert.Equal(t, sazed.JoinSeparatorSemicolon, separator)
+	_, err = sazed.ParseJoinSeparator("|")
+	assert.ErrorContains(t, err, `unknown separator "|"`)
+}
+
+func TestCombineMemories(t *testing.T) {
+	memory := sazed.CombineMemories([]sazed.Memory{memory1(), memory5()}, sazed.JoinSeparatorAnd)
+	assert.Equal(t, "cmd1 && echo {{value1}} {{value2}} end", memory.Command)
+	assert.Equal(t, "Memory 1; not bar", memory.Description)
+	assert.Equal(t, 2, memory.Template.CountPlaceholders())
+}
+
+func TestMultiSelect(t *testing.T) {
+	newModel := func(separator sazed.JoinSeparator) sazed.Model {
+		m := newTestModel(func(opts *sazed.AppOptions) {
+			opts.CommandPrintLength = 10
+			opts.Separator = separator
+		})
+		return update(m, sazed.LoadedMemories([]sazed.Memory{memory1(), memory2(), memory5()}))
+	}
+	t.Run("tab toggles marks", func(t *testing.T) {
+		m := newModel(sazed.JoinSeparatorAnd)
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, []int{0, 1}, m.MarkedMemories)
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyUp})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, []int{1}, m.MarkedMemories)
+	})
+	t.Run("marks are shown", func(t *testing.T) {
+		m := newModel(sazed.JoinSeparatorAnd)
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		m = update(m, tea.KeyMsg{Type: tea.KeyUp})
+
+		lines := strings.Split(sazed.ViewCommandSelection(m), "\n")
+
+		assert.Equal(t, "2/3 (2 marked) ----------------------", lines[2])
+		assert.Equal(t, "*  cmd1      ", lines[3])
+		assert.Equal(t, ">> foo       ", lines[5])
+		assert.Equal(t, "*  echo {{val", lines[7])
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyUp})
+		lines = strings.Split(sazed.ViewCommandSelection(m), "\n")
+		assert.Equal(t, ">* cmd1      ", lines[3])
+	})
+	t.Run("outputs marked memories joined in mark order", func(t *testing.T) {
+		defer cleanup()
+		m := newModel(sazed.JoinSeparatorSemicolon)
+		m = update(m, tea.KeyMsg{Type: tea.KeyDown})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		m = update(m, tea.KeyMsg{Type: tea.KeyUp})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, "foo; cmd1", sazed.QuitOutput)
+		assert.Equal(t, []sazed.Memory{memory2(), memory1()}, m.SelectedMemories)
+	})
+	t.Run("edits placeholders of all marked memories", func(t *testing.T) {
+		defer cleanup()
+		m := newModel(sazed.JoinSeparatorNewline)
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnd})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageEdit, m.CurrentPage)
+		assert.Len(t, m.EditTextInputs, 2)
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, "cmd1\necho a b end", sazed.QuitOutput)
+	})
+}
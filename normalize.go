@@ -0,0 +1,136 @@
+// This file contains the normalisation of user input and memories before
+// matching: smart-case and accent folding.
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// IsCaseSensitive returns true if a search for `input` should be case
+// sensitive: with smart-case, only if the input has an uppercase letter.
+func IsCaseSensitive(input string, smartCase bool) bool {
+	return smartCase && strings.IndexFunc(input, unicode.IsUpper) != -1
+}
+
+// FoldAccents removes accents and other diacritics from `s` (e.g.
+// "configuração" becomes "configuracao"). It also returns, for each byte of
+// the folded string, the byte offset of the rune it came from in `s`. The
+// offsets are nil if `s` was not changed.
+func FoldAccents(s string) (string, []int) {
+	if isASCII(s) {
+		return s, nil
+	}
+	folded := strings.Builder{}
+	offsets := make([]int, 0, len(s))
+	for i, r := range s {
+		for _, decomposed := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, decomposed) {
+				continue
+			}
+			folded.WriteRune(decomposed)
+			for range utf8.RuneLen(decomposed) {
+				offsets = append(offsets, i)
+			}
+		}
+	}
+	return folded.String(), offsets
+}
+
+// unfoldIndexes maps byte indexes of a folded string back to the original
+func unfoldIndexes(indexes []int, offsets []int) []int {
+	if offsets == nil || indexes == nil {
+		return indexes
+	}
+	unfolded := []int{}
+	for _, index := range indexes {
+		if index < len(offsets) && (len(unfolded) == 0 || unfolded[len(unfolded)-1] != offsets[index]) {
+			unfolded = append(unfolded, offsets[index])
+		}
+	}
+	return unfolded
+}
+
+// FoldedText is the accent folded text of a memory (see FoldAccents)
+type FoldedText struct {
+	Command            string
+	CommandOffsets     []int
+	Description        string
+	DescriptionOffsets []int
+	Keywords           []string
+	// Changed is false if folding changed nothing, so the memory is searched
+	// as is
+	Changed bool
+}
+
+// FoldMemory folds the accents of the Command, Description and Keywords of `m`
+func FoldMemory(m Memory) *FoldedText {
+	folded := FoldedText{}
+	folded.Command, folded.CommandOffsets = FoldAccents(m.Command)
+	folded.Description, folded.DescriptionOffsets = FoldAccents(m.Description)
+	folded.Changed = folded.CommandOffsets != nil || folded.DescriptionOffsets != nil
+	for _, keyword := range m.Keywords {
+		foldedKeyword, offsets := FoldAccents(keyword)
+		folded.Keywords = append(folded.Keywords, foldedKeyword)
+		folded.Changed = folded.Changed || offsets != nil
+	}
+	return &folded
+}
+
+// GetFolded returns the folded text of `m`, folding it if needed.
+func (m Memory) GetFolded() *FoldedText {
+	if m.Folded != nil {
+		return m.Folded
+	}
+	return FoldMemory(m)
+}
+
+// FoldAccentsSearch wraps a search mode, folding accents of both the input
+// and the memories before matching. Memories are folded when loaded (see
+// CompileMemory), so only the input is folded on each search.
+type FoldAccentsSearch struct {
+	Inner IFuzzy
+}
+
+// GetMatches implements IFuzzy
+func (s FoldAccentsSearch) GetMatches(memories []Memory, input string) []Match {
+	foldedInput, _ := FoldAccents(input)
+	// Memories are only copied if some of them have accents
+	foldedMemories := memories
+	folds := map[int]*FoldedText{}
+	for i, memory := range memories {
+		folded := memory.GetFolded()
+		if !folded.Changed {
+			continue
+		}
+		if len(folds) == 0 {
+			foldedMemories = slices.Clone(memories)
+		}
+		folds[i] = folded
+		foldedMemories[i].Command = folded.Command
+		foldedMemories[i].Description = folded.Description
+		foldedMemories[i].Keywords = folded.Keywords
+	}
+	matches := s.Inner.GetMatches(foldedMemories, foldedInput)
+	for i, match := range matches {
+		matches[i].Memory = memories[match.Index]
+		if folded, ok := folds[match.Index]; ok {
+			matches[i].CommandMatchedIndexes = unfoldIndexes(match.CommandMatchedIndexes, folded.CommandOffsets)
+			matches[i].DescriptionMatchedIndexes = unfoldIndexes(match.DescriptionMatchedIndexes, folded.DescriptionOffsets)
+		}
+	}
+	return matches
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__IsCaseSensitive(t *testing.T) {
+	assert.False(t, sazed.IsCaseSensitive("docker", true))
+	assert.True(t, sazed.IsCaseSensitive("Docker", true))
+	assert.True(t, sazed.IsCaseSensitive("ÇÃO", true))
+	assert.False(t, sazed.IsCaseSensitive("Docker", false))
+}
+
+func Test__FoldAccents(t *testing.T) {
+	t.Run("ASCII is unchanged", func(t *testing.T) {
+		folded, offsets := sazed.FoldAccents("docker ps")
+		assert.Equal(t, "docker ps", folded)
+		assert.Nil(t, offsets)
+	})
+	t.Run("folds diacritics", func(t *testing.T) {
+		folded, offsets := sazed.FoldAccents("ação Ñ")
+		assert.Equal(t, "acao N", folded)
+		assert.Equal(t, []int{0, 1, 3, 5, 6, 7}, offsets)
+	})
+}
+
+func Test__FoldAccentsSearch(t *testing.T) {
+	memories := []sazed.Memory{
+		{Command: "vim ~/.config", Description: "Abre a configuração"},
+		{Command: "ls", Description: "Lista arquivos"},
+	}
+	for _, mode := range sazed.SearchModes {
+		t.Run(string(mode), func(t *testing.T) {
+			opts := sazed.DefaultSearchOptions()
+			matches := sazed.NewSearcher(mode, opts).GetMatches(memories, "configuracao")
+			assert.Len(t, matches, 1)
+			assert.Equal(t, memories[0], matches[0].Memory)
+		})
+	}
+	t.Run("maps indexes back to the original", func(t *testing.T) {
+		opts := sazed.DefaultSearchOptions()
+		matches := sazed.NewSearcher(sazed.SearchModeSubstring, opts).GetMatches(memories, "cao")
+		assert.Equal(t, []int{16, 18, 20}, matches[0].DescriptionMatchedIndexes)
+	})
+	t.Run("accented input matches unaccented memories", func(t *testing.T) {
+		opts := sazed.DefaultSearchOptions()
+		matches := sazed.NewSearcher(sazed.SearchModeFuzzy, opts).GetMatches(memories, "arquivós")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[1], matches[0].Memory)
+	})
+}
+
+func Test__FoldMemory(t *testing.T) {
+	t.Run("folded when loaded", func(t *testing.T) {
+		memory := sazed.CompileMemory(sazed.Memory{Command: "ls", Description: "Lista a seção", Keywords: []string{"ação"}})
+		assert.Equal(t, "Lista a secao", memory.Folded.Description)
+		assert.Equal(t, []string{"acao"}, memory.Folded.Keywords)
+		assert.True(t, memory.Folded.Changed)
+		assert.False(t, sazed.CompileMemory(sazed.Memory{Command: "ls"}).Folded.Changed)
+	})
+	t.Run("search uses the folded text of loaded memories", func(t *testing.T) {
+		memory := sazed.CompileMemory(sazed.Memory{Command: "ls", Description: "seção"})
+		memory.Folded.Description = "cached"
+		matches := sazed.NewSearcher(sazed.SearchModeSubstring, sazed.DefaultSearchOptions()).GetMatches([]sazed.Memory{memory}, "cached")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memory, matches[0].Memory)
+	})
+}
+
+func Test__SmartCase(t *testing.T) {
+	memories := []sazed.Memory{
+		{Command: "docker ps", Description: "List containers"},
+		{Command: "Docker ps", Description: "list Containers"},
+	}
+	for _, mode := range sazed.SearchModes {
+		t.Run(string(mode), func(t *testing.T) {
+			searcher := sazed.NewSearcher(mode, sazed.DefaultSearchOptions())
+			assert.Len(t, searcher.GetMatches(memories, "docker"), 2)
+			matches := searcher.GetMatches(memories, "Docker")
+			if mode != sazed.SearchModeTypo {
+				// For typo, a different case is one typo, so it only ranks lower
+				assert.Len(t, matches, 1)
+			}
+			assert.Equal(t, memories[1], matches[0].Memory)
+		})
+	}
+	t.Run("disabled", func(t *testing.T) {
+		opts := sazed.DefaultSearchOptions()
+		opts.SmartCase = false
+		assert.Len(t, sazed.NewSearcher(sazed.SearchModeFuzzy, opts).GetMatches(memories, "Docker"), 2)
+	})
+	t.Run("fuzzy indexes are case sensitive", func(t *testing.T) {
+		memories := []sazed.Memory{{Command: "docker ps -a Docker"}}
+		matches := sazed.NewSearcher(sazed.SearchModeFuzzy, sazed.DefaultSearchOptions()).GetMatches(memories, "Dk")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, []int{13, 16}, matches[0].CommandMatchedIndexes)
+	})
+}
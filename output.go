@@ -0,0 +1,119 @@
+// This file contains the output sinks, where the chosen command is written
+// to (see the --output option).
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// OutputSink receives the command chosen by the user
+type OutputSink interface {
+	Output(command string) error
+}
+
+// WriterSink writes the command to Writer, e.g. stdout or a file descriptor
+type WriterSink struct {
+	Writer io.Writer
+}
+
+func (s WriterSink) Output(command string) error {
+	_, err := fmt.Fprint(s.Writer, command)
+	return err
+}
+
+// FileSink writes the command to the file at Path, replacing its content
+type FileSink struct {
+	Path string
+}
+
+func (s FileSink) Output(command string) error {
+	if err := os.WriteFile(s.Path, []byte(command), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// OSC52Sink copies the command to the clipboard with the OSC52 escape
+// sequence, written to Terminal. It works over SSH if the local terminal
+// supports it.
+type OSC52Sink struct {
+	Terminal io.Writer
+}
+
+func (s OSC52Sink) Output(command string) error {
+	_, err := fmt.Fprintf(s.Terminal, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(command)))
+	return err
+}
+
+// TmuxBufferSink copies the command to a tmux paste buffer. Run runs a
+// program with its arguments, and defaults to RunProgram.
+type TmuxBufferSink struct {
+	Run func(name string, args ...string) error
+}
+
+func (s TmuxBufferSink) Output(command string) error {
+	run := s.Run
+	if run == nil {
+		run = RunProgram
+	}
+	if err := run("tmux", "set-buffer", "--", command); err != nil {
+		return fmt.Errorf("failed to set tmux buffer: %w", err)
+	}
+	return nil
+}
+
+// RunProgram runs the program `name` with `args`, waiting for it to finish
+func RunProgram(name string, args ...string) error {
+	return exec.Command(name, args...).Run()
+}
+
+// ParseOutput splits an --output value in its kind (stdout, fd, file, osc52
+// or tmux-buffer) and argument, checking that it's valid.
+func ParseOutput(s string) (kind string, arg string, err error) {
+	kind, arg, _ = strings.Cut(s, ":")
+	switch kind {
+	case "stdout", "osc52", "tmux-buffer":
+		if arg != "" {
+			return "", "", fmt.Errorf("output %q does not take an argument", kind)
+		}
+	case "fd":
+		if fd, err := strconv.Atoi(arg); err != nil || fd < 0 {
+			return "", "", fmt.Errorf("invalid file descriptor %q", arg)
+		}
+	case "file":
+		if arg == "" {
+			return "", "", fmt.Errorf("missing path for file output")
+		}
+	default:
+		return "", "", fmt.Errorf("unknown output %q", s)
+	}
+	return kind, arg, nil
+}
+
+// NewOutputSink returns the sink for an --output value. The terminal is used
+// for escape sequences, since stdout may be captured.
+func NewOutputSink(s string, terminal io.Writer) (OutputSink, error) {
+	kind, arg, err := ParseOutput(s)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "fd":
+		fd, _ := strconv.Atoi(arg)
+		return WriterSink{Writer: os.NewFile(uintptr(fd), "fd:"+arg)}, nil
+	case "file":
+		return FileSink{Path: arg}, nil
+	case "osc52":
+		return OSC52Sink{Terminal: terminal}, nil
+	case "tmux-buffer":
+		return TmuxBufferSink{}, nil
+	default:
+		return WriterSink{Writer: os.Stdout}, nil
+	}
+}
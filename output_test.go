@@ -0,0 +1,86 @@
+package main_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestParseOutput(t *testing.T) {
+	for _, test := range []struct{ value, kind, arg string }{
+		{"stdout", "stdout", ""},
+		{"fd:3", "fd", "3"},
+		{"file:/tmp/foo:bar", "file", "/tmp/foo:bar"},
+		{"osc52", "osc52", ""},
+		{"tmux-buffer", "tmux-buffer", ""},
+	} {
+		kind, arg, err := sazed.ParseOutput(test.value)
+		assert.Nil(t, err, test.value)
+		assert.Equal(t, test.kind, kind)
+		assert.Equal(t, test.arg, arg)
+	}
+	for value, expectedErr := range map[string]string{
+		"foo":      `unknown output "foo"`,
+		"fd:x":     `invalid file descriptor "x"`,
+		"fd:-1":    `invalid file descriptor "-1"`,
+		"file:":    "missing path for file output",
+		"stdout:x": `output "stdout" does not take an argument`,
+	} {
+		_, _, err := sazed.ParseOutput(value)
+		assert.ErrorContains(t, err, expectedErr, value)
+	}
+}
+
+func TestNewOutputSink(t *testing.T) {
+	terminal := bytes.Buffer{}
+	sink, err := sazed.NewOutputSink("stdout", &terminal)
+	assert.Nil(t, err)
+	assert.Equal(t, sazed.WriterSink{Writer: os.Stdout}, sink)
+
+	sink, err = sazed.NewOutputSink("file:/foo", &terminal)
+	assert.Nil(t, err)
+	assert.Equal(t, sazed.FileSink{Path: "/foo"}, sink)
+
+	sink, err = sazed.NewOutputSink("osc52", &terminal)
+	assert.Nil(t, err)
+	assert.Equal(t, sazed.OSC52Sink{Terminal: &terminal}, sink)
+
+	_, err = sazed.NewOutputSink("foo", &terminal)
+	assert.ErrorContains(t, err, `unknown output "foo"`)
+}
+
+func TestOutputSinks(t *testing.T) {
+	t.Run("writer", func(t *testing.T) {
+		out := bytes.Buffer{}
+		assert.Nil(t, sazed.WriterSink{Writer: &out}.Output("ls -la"))
+		assert.Equal(t, "ls -la", out.String())
+	})
+	t.Run("file", func(t *testing.T) {
+		outputFile := path.Join(t.TempDir(), "output")
+		assert.Nil(t, sazed.FileSink{Path: outputFile}.Output("ls -la"))
+		content, _ := os.ReadFile(outputFile)
+		assert.Equal(t, "ls -la", string(content))
+	})
+	t.Run("osc52", func(t *testing.T) {
+		terminal := bytes.Buffer{}
+		assert.Nil(t, sazed.OSC52Sink{Terminal: &terminal}.Output("ls -la"))
+		assert.Equal(t, "\x1b]52;c;bHMgLWxh\a", terminal.String())
+	})
+	t.Run("tmux buffer", func(t *testing.T) {
+		var ran []string
+		sink := sazed.TmuxBufferSink{Run: func(name string, args ...string) error {
+			ran = append([]string{name}, args...)
+			return nil
+		}}
+		assert.Nil(t, sink.Output("-ls"))
+		assert.Equal(t, []string{"tmux", "set-buffer", "--", "-ls"}, ran)
+	})
+	t.Run("tmux buffer error", func(t *testing.T) {
+		sink := sazed.TmuxBufferSink{Run: func(string, ...string) error { return os.ErrNotExist }}
+		assert.ErrorContains(t, sink.Output("ls"), "failed to set tmux buffer")
+	})
+}
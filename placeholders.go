@@ -1,68 +1,80 @@
 package main
 
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
 func CountPlaceholders(s string) int {
-	return len(GetPlaceholders(s))
+	return CompileTemplate(s).CountPlaceholders()
 }
 
+// Placeholder is a `{{name}}` found in a command. `Beg` and `End` are the
+// byte offsets of the first `{` and of the last `}`.
 type Placeholder struct {
 	Beg  int
 	End  int
 	Name string
 }
 
-func GetPlaceholders(s string) []Placeholder {
-	insideBrackets := false
+// ParseError describes an invalid placeholder in a command
+type ParseError struct {
+	// Offset is the byte offset where the problem starts
+	Offset int
+	// Column is the (0-based) rune offset where the problem starts
+	Column int
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column+1, e.Reason)
+}
+
+// ParsePlaceholders returns all placeholders in `s`. If `s` contains an
+// invalid placeholder, a *ParseError is returned together with the
+// placeholders found before it.
+func ParsePlaceholders(s string) ([]Placeholder, error) {
 	placeholders := []Placeholder{}
-	currentPlaceholder := Placeholder{}
-	for i := 0; i < len(s)-1; i++ {
-		char := s[i]
-		nextChar := s[i+1]
-		if insideBrackets {
-			if char == '}' && nextChar == '}' {
-				insideBrackets = false
-				currentPlaceholder.End = i + 1
-				placeholders = append(placeholders, currentPlaceholder)
-				currentPlaceholder = Placeholder{}
-			} else {
-				currentPlaceholder.Name += string(char)
-			}
-		} else {
-			if char == '{' && nextChar == '{' {
-				insideBrackets = true
-				currentPlaceholder.Beg = i
-				i++
+	column := 0
+	open := -1 // byte offset of the current `{{`, -1 if outside a placeholder
+	openColumn := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return placeholders, &ParseError{Offset: i, Column: column, Reason: "invalid UTF-8"}
+		}
+		if open == -1 && strings.HasPrefix(s[i:], "{{") {
+			open, openColumn = i, column
+			i, column = i+2, column+2
+			continue
+		}
+		if open != -1 && strings.HasPrefix(s[i:], "}}") {
+			name := s[open+2 : i]
+			if name == "" {
+				return placeholders, &ParseError{Offset: open, Column: openColumn, Reason: "empty placeholder name"}
 			}
+			placeholders = append(placeholders, Placeholder{Beg: open, End: i + 1, Name: name})
+			open = -1
+			i, column = i+2, column+2
+			continue
 		}
+		i, column = i+size, column+1
 	}
-	return placeholders
-}
-
-func NextPlaceholder(s string) (p Placeholder, success bool) {
-	placeholders := GetPlaceholders(s)
-	if len(placeholders) == 0 {
-		return Placeholder{}, false
+	if open != -1 {
+		return placeholders, &ParseError{Offset: open, Column: openColumn, Reason: "unterminated placeholder"}
 	}
-	return placeholders[0], true
+	return placeholders, nil
 }
 
-func ReplacePlaceholder(s string, p Placeholder, replacement string) string {
-	beg := s[:p.Beg]
-	end := s[p.End+1:]
-	return beg + replacement + end
+// GetPlaceholders returns all valid placeholders in `s`, ignoring parse errors.
+func GetPlaceholders(s string) []Placeholder {
+	placeholders, _ := ParsePlaceholders(s)
+	return placeholders
 }
 
 // Given a string `s` with placeholders like `{{foo}}`, replace them with the values in `placeholderValues`. The `i`th placeholder should be replaced with the `i`th value in `placeholderValues`.
-func Render(s string, placeholderValues []string) string {
-	for i := 0; true; i++ {
-		placeholder, success := NextPlaceholder(s)
-		if !success {
-			break
-		}
-		value := ""
-		if i < len(placeholderValues) {
-			value = placeholderValues[i]
-		}
-		s = ReplacePlaceholder(s, placeholder, value)
-	}
-	return s
+// Returns an error if `s` contains an invalid placeholder, so that a half-rendered command is never used.
+func Render(s string, placeholderValues []string) (string, error) {
+	return CompileTemplate(s).Render(placeholderValues)
 }
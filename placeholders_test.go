@@ -34,68 +34,26 @@ func Test__getPlaceholdersIndexes(t *testing.T) {
 	assert.Equal(t, []sazed.Placeholder{}, sazed.GetPlaceholders("{foo} bar {baz}}"))
 }
 
-func Test_NextPlaceholder(t *testing.T) {
-	td := []struct {
-		original string
-		expected sazed.Placeholder
-	}{
-		{
-			original: "echo {{foo}}",
-			expected: sazed.Placeholder{5, 11, "foo"},
-		},
-		{
-			original: "echo {{foo}} bar",
-			expected: sazed.Placeholder{5, 11, "foo"},
-		},
-		{
-			original: "{{foo}} bar baz",
-			expected: sazed.Placeholder{0, 6, "foo"},
-		},
-		{
-			original: "foo bar baz",
-			expected: sazed.Placeholder{},
-		},
-	}
-	for i, tc := range td {
-		t.Run(fmt.Sprintf("%s [%d]", tc.original, i), func(t *testing.T) {
-			placeholder, success := sazed.NextPlaceholder(tc.original)
-			assert.Equal(t, tc.expected, placeholder)
-			assert.Equal(t, tc.expected != sazed.Placeholder{}, success)
-		})
-	}
-}
-
-func Test__ReplacePlaceholder(t *testing.T) {
-	td := []struct {
-		original    string
-		placeholder sazed.Placeholder
-		replacement string
-		expected    string
-	}{
-		{
-			original:    "echo {{foo}}",
-			placeholder: sazed.Placeholder{5, 11, ""},
-			replacement: "baz",
-			expected:    "echo baz",
-		},
-		{
-			original:    "echo {{foo}} bar",
-			placeholder: sazed.Placeholder{5, 11, ""},
-			replacement: "baz",
-			expected:    "echo baz bar",
-		},
-		{
-			original:    "{{foo}} bar baz",
-			placeholder: sazed.Placeholder{0, 6, ""},
-			replacement: "foo",
-			expected:    "foo bar baz",
-		},
-	}
-	for i, tc := range td {
-		t.Run(fmt.Sprintf("%s [%d]", tc.original, i), func(t *testing.T) {
-			assert.Equal(t, tc.expected, sazed.ReplacePlaceholder(tc.original, tc.placeholder, tc.replacement))
-		})
-	}
+func Test__ParsePlaceholders(t *testing.T) {
+	t.Run("non-ASCII names", func(t *testing.T) {
+		placeholders, err := sazed.ParsePlaceholders("echo {{função}} {{日本}}")
+		assert.Nil(t, err)
+		assert.Equal(t, []sazed.Placeholder{{5, 16, "função"}, {18, 27, "日本"}}, placeholders)
+	})
+	t.Run("unterminated placeholder", func(t *testing.T) {
+		placeholders, err := sazed.ParsePlaceholders("ação {{foo}} {{bar")
+		assert.Equal(t, []sazed.Placeholder{{7, 13, "foo"}}, placeholders)
+		assert.Equal(t, &sazed.ParseError{Offset: 15, Column: 13, Reason: "unterminated placeholder"}, err)
+		assert.EqualError(t, err, "column 14: unterminated placeholder")
+	})
+	t.Run("empty placeholder name", func(t *testing.T) {
+		_, err := sazed.ParsePlaceholders("foo {{}}")
+		assert.Equal(t, &sazed.ParseError{Offset: 4, Column: 4, Reason: "empty placeholder name"}, err)
+	})
+	t.Run("invalid UTF-8", func(t *testing.T) {
+		_, err := sazed.ParsePlaceholders("foo \xff {{bar}}")
+		assert.Equal(t, &sazed.ParseError{Offset: 4, Column: 4, Reason: "invalid UTF-8"}, err)
+	})
 }
 
 func Test__Render(t *testing.T) {
@@ -127,7 +85,14 @@ func Test__Render(t *testing.T) {
 	}
 	for i, tc := range td {
 		t.Run(fmt.Sprintf("%s [%d]", tc.original, i), func(t *testing.T) {
-			assert.Equal(t, tc.expected, sazed.Render(tc.original, tc.placeholderValues))
+			rendered, err := sazed.Render(tc.original, tc.placeholderValues)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, rendered)
 		})
 	}
+	t.Run("errors on invalid placeholder", func(t *testing.T) {
+		rendered, err := sazed.Render("echo {{foo}} {{bar", []string{"a", "b"})
+		assert.Equal(t, "", rendered)
+		assert.ErrorContains(t, err, "unterminated placeholder")
+	})
 }
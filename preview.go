@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PreviewSideMinWidth is the minimum terminal width to show the preview at
+// the side of the matches. Narrower terminals show it at the bottom.
+const PreviewSideMinWidth = 100
+
+// PreviewSeparator separates the matches from the preview at the side
+const PreviewSeparator = " | "
+
+// TogglePreview shows or hides the preview of the match under the cursor
+func TogglePreview(m Model) Model {
+	m.ShowPreview = !m.ShowPreview
+	return ScrollToCursor(m)
+}
+
+// PreviewOnSide returns whether the preview is shown at the side of the
+// matches, instead of at the bottom.
+func PreviewOnSide(m Model) bool {
+	return m.Width >= PreviewSideMinWidth
+}
+
+// PreviewBottomLines returns how many lines the preview uses at the bottom of
+// the selection page. It's zero if the preview is hidden or on the side.
+func PreviewBottomLines(m Model) int {
+	if !m.ShowPreview || m.Height <= 0 || PreviewOnSide(m) {
+		return 0
+	}
+	return m.Height / 2
+}
+
+// ViewPreview renders the full command, description, placeholders, cwd, env
+// and source of `memory`, wrapped at `width` runes and cut at `height` lines.
+// Use -1 for no limits.
+func ViewPreview(memory Memory, width int, height int) string {
+	lines := []string{"Command:"}
+	lines = append(lines, Wrap(memory.Command, width)...)
+	lines = append(lines, "Description:")
+	lines = append(lines, Wrap(memory.Description, width)...)
+	placeholders := "(none)"
+	if names := PlaceholderNames(memory); len(names) > 0 {
+		placeholders = strings.Join(names, ", ")
+	}
+	lines = append(lines, "Placeholders:")
+	lines = append(lines, Wrap(placeholders, width)...)
+	if memory.Cwd != "" {
+		lines = append(lines, "Cwd:")
+		lines = append(lines, Wrap(memory.Cwd, width)...)
+	}
+	if len(memory.Env) > 0 {
+		lines = append(lines, "Env:")
+		for _, name := range envNames(memory) {
+			lines = append(lines, Wrap(name+"="+memory.Env[name], width)...)
+		}
+	}
+	if memory.Source != "" {
+		lines = append(lines, "Source:")
+		lines = append(lines, Wrap(memory.Source, width)...)
+	}
+	if height >= 0 {
+		lines = lines[:min(height, len(lines))]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PlaceholderNames returns the unique placeholder names of `memory`, in the
+// order they first appear.
+func PlaceholderNames(memory Memory) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	placeholders, _ := MemoryPlaceholders(memory)
+	for _, placeholder := range placeholders {
+		if !seen[placeholder.Name] {
+			seen[placeholder.Name] = true
+			names = append(names, placeholder.Name)
+		}
+	}
+	return names
+}
+
+// Wrap splits `s` in lines of at most `width` runes. Newlines in `s` are kept.
+// Use -1 for no limit.
+func Wrap(s string, width int) []string {
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if width <= 0 {
+			lines = append(lines, line)
+			continue
+		}
+		for {
+			head := Truncate(line, width)
+			lines = append(lines, head)
+			line = line[len(head):]
+			if line == "" {
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// joinPreviewOnSide renders `matches` and `preview` side by side
+func joinPreviewOnSide(matches string, preview string) string {
+	separator := strings.TrimSuffix(strings.Repeat(PreviewSeparator+"\n", strings.Count(matches, "\n")+1), "\n")
+	return lipgloss.JoinHorizontal(lipgloss.Top, matches, separator, preview)
+}
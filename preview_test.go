@@ -0,0 +1,98 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestWrap(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		assert.Equal(t, []string{"foo bar"}, sazed.Wrap("foo bar", -1))
+	})
+	t.Run("wraps at width", func(t *testing.T) {
+		assert.Equal(t, []string{"foo", " ba", "r"}, sazed.Wrap("foo bar", 3))
+	})
+	t.Run("keeps newlines", func(t *testing.T) {
+		assert.Equal(t, []string{"ab", "c", "d"}, sazed.Wrap("abc\nd", 2))
+	})
+	t.Run("non-ASCII", func(t *testing.T) {
+		assert.Equal(t, []string{"aç", "ão"}, sazed.Wrap("ação", 2))
+	})
+}
+
+func TestPlaceholderNames(t *testing.T) {
+	memory := sazed.Memory{Command: "echo {{foo}} {{bar}} {{foo}}"}
+	assert.Equal(t, []string{"foo", "bar"}, sazed.PlaceholderNames(memory))
+	assert.Equal(t, []string{}, sazed.PlaceholderNames(sazed.Memory{Command: "ls"}))
+}
+
+func TestViewPreview(t *testing.T) {
+	memory := sazed.CompileMemory(sazed.Memory{
+		Command:     "docker run {{image}}",
+		Description: "Runs an image",
+		Source:      "/memories.yaml",
+	})
+	t.Run("renders everything", func(t *testing.T) {
+		lines := strings.Split(sazed.ViewPreview(memory, -1, -1), "\n")
+		assert.Equal(t, []string{
+			"Command:",
+			"docker run {{image}}",
+			"Description:",
+			"Runs an image",
+			"Placeholders:",
+			"image",
+			"Source:",
+			"/memories.yaml",
+		}, lines)
+	})
+	t.Run("fits width and height", func(t *testing.T) {
+		lines := strings.Split(sazed.ViewPreview(memory, 10, 4), "\n")
+		assert.Equal(t, []string{"Command:", "docker run", " {{image}}", "Description:"}, lines)
+	})
+	t.Run("no placeholders nor source", func(t *testing.T) {
+		lines := strings.Split(sazed.ViewPreview(sazed.Memory{Command: "ls"}, -1, -1), "\n")
+		assert.Equal(t, []string{"Command:", "ls", "Description:", "", "Placeholders:", "(none)"}, lines)
+	})
+}
+
+func TestViewCommandSelectionPreview(t *testing.T) {
+	newModel := func(width, height int) sazed.Model {
+		model := newTestModel(func(opts *sazed.AppOptions) { opts.CommandPrintLength = 75 })
+		model.Matches = manyMatches(20)
+		model = update(model, tea.WindowSizeMsg{Width: width, Height: height})
+		return update(model, tea.KeyMsg{Type: tea.KeyCtrlO})
+	}
+	t.Run("toggles with ctrl+o", func(t *testing.T) {
+		model := newModel(20, 15)
+		assert.True(t, model.ShowPreview)
+		model = update(model, tea.KeyMsg{Type: tea.KeyCtrlO})
+		assert.False(t, model.ShowPreview)
+	})
+	t.Run("at the bottom of narrow terminals", func(t *testing.T) {
+		model := newModel(20, 15)
+
+		lines := strings.Split(sazed.ViewCommandSelection(model), "\n")
+
+		// 7 lines of preview leave room for 2 matches
+		assert.Equal(t, 2, sazed.VisibleMatchesCount(model))
+		assert.Len(t, lines, 3+2*2+7+1)
+		assert.Equal(t, "----------------------", lines[7])
+		assert.Equal(t, "Command:", lines[8])
+		assert.Equal(t, "cmd0", lines[9])
+		assert.Equal(t, "Description:", lines[10])
+	})
+	t.Run("at the side of wide terminals", func(t *testing.T) {
+		model := newModel(100, 14)
+
+		lines := strings.Split(sazed.ViewCommandSelection(model), "\n")
+
+		assert.Equal(t, 5, sazed.VisibleMatchesCount(model))
+		assert.Len(t, lines, 3+5*2+1)
+		assert.Equal(t, ">> cmd0"+strings.Repeat(" ", 43)+" | Command:", strings.TrimRight(lines[3], " "))
+		assert.Equal(t, "      |"+strings.Repeat(" ", 43)+" | cmd0", strings.TrimRight(lines[4], " "))
+	})
+}
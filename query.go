@@ -0,0 +1,235 @@
+// This file contains the extended query syntax used by Fuzzy, inspired by
+// fzf: space separated terms that must all match, `!term` to exclude,
+// `'term` for exact matches, `^`/`$` anchors and `cmd:`/`desc:` to restrict
+// a term to one field.
+package main
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// QueryField is the memory field a query term is matched against
+type QueryField string
+
+const QueryFieldAny QueryField = ""
+const QueryFieldCommand QueryField = "cmd"
+const QueryFieldDescription QueryField = "desc"
+
+// QueryTerm is a single term of an extended query
+type QueryTerm struct {
+	Text  string
+	Field QueryField
+	// Negate excludes memories matching the term
+	Negate bool
+	// Exact matches Text as a substring instead of fuzzy
+	Exact bool
+	// Prefix anchors Text at the beginning of the field
+	Prefix bool
+	// Suffix anchors Text at the end of the field
+	Suffix bool
+	// Synonyms are matched like Text (see Synonyms)
+	Synonyms []string
+	// CaseSensitive disables case folding (see IsCaseSensitive)
+	CaseSensitive bool
+}
+
+// ParseQuery parses the user input into query terms. Terms without text
+// (e.g. a lone `!` while the user is typing) are ignored.
+func ParseQuery(input string) []QueryTerm {
+	terms := []QueryTerm{}
+	for _, token := range strings.Fields(input) {
+		term := QueryTerm{}
+		if strings.HasPrefix(token, "!") {
+			term.Negate = true
+			token = token[1:]
+		}
+		for _, field := range []QueryField{QueryFieldCommand, QueryFieldDescription} {
+			if strings.HasPrefix(token, string(field)+":") {
+				term.Field = field
+				token = token[len(field)+1:]
+			}
+		}
+		if strings.HasPrefix(token, "'") {
+			term.Exact = true
+			token = token[1:]
+		}
+		if strings.HasPrefix(token, "^") {
+			term.Prefix = true
+			token = token[1:]
+		}
+		if strings.HasSuffix(token, "$") {
+			term.Suffix = true
+			token = token[:len(token)-1]
+		}
+		if token == "" {
+			continue
+		}
+		term.Text = token
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// MatchField matches the term, or the best of it's synonyms, against a
+// single field. Negation is ignored.
+func (t QueryTerm) MatchField(field string) (score int, matchedIndexes []int, ok bool) {
+	score, matchedIndexes, ok = t.matchText(t.Text, field)
+	for _, synonym := range t.Synonyms {
+		synonymScore, synonymIndexes, synonymOk := t.matchSynonym(synonym, field)
+		if synonymOk && (!ok || synonymScore > score) {
+			score, matchedIndexes, ok = synonymScore, synonymIndexes, true
+		}
+	}
+	return score, matchedIndexes, ok
+}
+
+// matchText matches `text` against a field, using the term modifiers
+func (t QueryTerm) matchText(text string, field string) (score int, matchedIndexes []int, ok bool) {
+	if t.Prefix || t.Suffix {
+		beg := 0
+		if t.Suffix {
+			beg = suffixStart(field, text)
+		}
+		if beg == -1 || (t.Prefix && beg != 0) {
+			return 0, nil, false
+		}
+		length, ok := hasPrefixMatch(field[beg:], text, t.CaseSensitive)
+		if !ok || (t.Suffix && beg+length != len(field)) {
+			return 0, nil, false
+		}
+		return positionScore(beg, length), rangeIndexes(beg, beg+length), true
+	}
+	if t.Exact || t.Negate {
+		for index := range field {
+			if length, ok := hasPrefixMatch(field[index:], text, t.CaseSensitive); ok {
+				return positionScore(index, length), rangeIndexes(index, index+length), true
+			}
+		}
+		return 0, nil, false
+	}
+	results := fuzzy.FindNoSort(text, []string{field})
+	if len(results) == 0 {
+		return 0, nil, false
+	}
+	if t.CaseSensitive && !matchesCase(text, field, results[0].MatchedIndexes) {
+		// The fuzzy library ignores case, so find a case sensitive subsequence instead
+		indexes, ok := subsequenceIndexes(text, field)
+		return results[0].Score, indexes, ok
+	}
+	return results[0].Score, results[0].MatchedIndexes, true
+}
+
+// matchSynonym matches `synonym` as a whole word, so that short synonyms
+// like `rm` don't fuzzy match most fields. Anchors still apply.
+func (t QueryTerm) matchSynonym(synonym string, field string) (score int, matchedIndexes []int, ok bool) {
+	if t.Prefix || t.Suffix {
+		return t.matchText(synonym, field)
+	}
+	for index := range field {
+		length, ok := hasPrefixMatch(field[index:], synonym, t.CaseSensitive)
+		if ok && isWordStart(field, index) && isWordEnd(field, index+length) {
+			return positionScore(index, length), rangeIndexes(index, index+length), true
+		}
+	}
+	return 0, nil, false
+}
+
+// matchesCase returns true if the runes of `field` at the byte `indexes` are
+// exactly the runes of `text`
+func matchesCase(text string, field string, indexes []int) bool {
+	i := 0
+	for _, r := range text {
+		if i >= len(indexes) {
+			return false
+		}
+		fieldRune, _ := utf8.DecodeRuneInString(field[indexes[i]:])
+		if fieldRune != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// subsequenceIndexes returns the byte indexes of the first (case sensitive)
+// occurrence of the runes of `text` in order in `field`
+func subsequenceIndexes(text string, field string) ([]int, bool) {
+	indexes := []int{}
+	runes := []rune(text)
+	for i, r := range field {
+		if len(indexes) < len(runes) && r == runes[len(indexes)] {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, len(indexes) == len(runes)
+}
+
+// MatchQuery matches a memory against all query terms. Negated terms never
+// add to the scores, they only exclude. Keywords count as part of the
+// description. The match is not ranked.
+func MatchQuery(memory Memory, terms []QueryTerm) (Match, bool) {
+	match := Match{Memory: memory}
+	for _, term := range terms {
+		matchedAny := false
+		if term.Field != QueryFieldDescription {
+			if score, indexes, ok := term.MatchField(memory.Command); ok {
+				matchedAny = true
+				match.CommandScore += score
+				match.CommandMatchedIndexes = mergeIndexes(match.CommandMatchedIndexes, indexes)
+			}
+		}
+		if term.Field != QueryFieldCommand {
+			if score, indexes, ok := term.MatchField(memory.Description); ok {
+				matchedAny = true
+				match.DescriptionScore += score
+				match.DescriptionMatchedIndexes = mergeIndexes(match.DescriptionMatchedIndexes, indexes)
+			}
+			for _, keyword := range memory.Keywords {
+				if score, _, ok := term.MatchField(keyword); ok {
+					matchedAny = true
+					match.DescriptionScore += score
+					break
+				}
+			}
+		}
+		if matchedAny == term.Negate {
+			return Match{}, false
+		}
+	}
+	return match, true
+}
+
+// suffixStart returns the byte index where a match for `suffix` would start
+// at the end of `s`, or -1 if `s` is too short. Case folding keeps the
+// number of runes, so it's enough to count them.
+func suffixStart(s string, suffix string) int {
+	beg := len(s)
+	for range suffix {
+		if beg == 0 {
+			return -1
+		}
+		_, size := utf8.DecodeLastRuneInString(s[:beg])
+		beg -= size
+	}
+	return beg
+}
+
+// mergeIndexes returns the sorted union of two lists of indexes
+func mergeIndexes(a []int, b []int) []int {
+	if a == nil {
+		return b
+	}
+	merged := append([]int{}, a...)
+	for _, index := range b {
+		if !slices.Contains(merged, index) {
+			merged = append(merged, index)
+		}
+	}
+	sort.Ints(merged)
+	return merged
+}
@@ -0,0 +1,68 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__ParseQuery(t *testing.T) {
+	t.Run("plain terms", func(t *testing.T) {
+		assert.Equal(t, []sazed.QueryTerm{{Text: "docker"}, {Text: "rm"}}, sazed.ParseQuery(" docker  rm "))
+	})
+	t.Run("modifiers", func(t *testing.T) {
+		assert.Equal(t, []sazed.QueryTerm{
+			{Text: "compose", Negate: true},
+			{Text: "remove", Field: sazed.QueryFieldDescription},
+			{Text: "docker", Field: sazed.QueryFieldCommand, Exact: true},
+			{Text: "git", Prefix: true},
+			{Text: "push", Suffix: true},
+			{Text: "ls", Negate: true, Field: sazed.QueryFieldCommand, Prefix: true, Suffix: true},
+		}, sazed.ParseQuery("!compose desc:remove cmd:'docker ^git push$ !cmd:^ls$"))
+	})
+	t.Run("ignores empty terms", func(t *testing.T) {
+		assert.Equal(t, []sazed.QueryTerm{}, sazed.ParseQuery("! cmd: ^ '"))
+	})
+}
+
+func Test__MatchQuery(t *testing.T) {
+	memory := sazed.Memory{Command: "docker rm -f foo", Description: "Remove a container"}
+	td := []struct {
+		query   string
+		matches bool
+	}{
+		{"docker", true},
+		{"docker remove", true},
+		{"docker !compose", true},
+		{"docker !foo", false},
+		{"!cmd:remove", true},
+		{"cmd:remove", false},
+		{"desc:remove", true},
+		{"'dkr", false},
+		{"dkr", true},
+		{"^docker", true},
+		{"^rm", false},
+		{"foo$", true},
+		{"docker$", false},
+		{"^remove$", false},
+		{"^remove container$", true},
+		{"desc:^remove", true},
+		{"desc:^REMOVE", true},
+	}
+	for _, tc := range td {
+		t.Run(tc.query, func(t *testing.T) {
+			_, ok := sazed.MatchQuery(memory, sazed.ParseQuery(tc.query))
+			assert.Equal(t, tc.matches, ok)
+		})
+	}
+	t.Run("sums scores and merges indexes of all terms", func(t *testing.T) {
+		match, ok := sazed.MatchQuery(memory, sazed.ParseQuery("cmd:'rm ^dock !compose"))
+		assert.True(t, ok)
+		assert.Equal(t, sazed.Match{
+			Memory:                memory,
+			CommandScore:          13 + 40,
+			CommandMatchedIndexes: []int{0, 1, 2, 3, 7, 8},
+		}, match)
+	})
+}
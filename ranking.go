@@ -0,0 +1,149 @@
+// This file contains the ranking pipeline, which scores and sorts the
+// matches of all search modes.
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// TieBreak is how matches with the same score are ordered
+type TieBreak string
+
+// TieBreakOrder keeps the order of the memories file
+const TieBreakOrder TieBreak = "order"
+
+// TieBreakLength puts shorter commands first
+const TieBreakLength TieBreak = "length"
+
+// TieBreakUsage puts the most used memories first
+const TieBreakUsage TieBreak = "usage"
+
+// TieBreaks are all tie breaks
+var TieBreaks = []TieBreak{TieBreakOrder, TieBreakLength, TieBreakUsage}
+
+// ParseTieBreak returns the TieBreak named `s`
+func ParseTieBreak(s string) (TieBreak, error) {
+	for _, tieBreak := range TieBreaks {
+		if string(tieBreak) == s {
+			return tieBreak, nil
+		}
+	}
+	return "", fmt.Errorf("unknown tie break %q", s)
+}
+
+// Ranking scores matches from their Command and Description scores and
+// sorts them. Sorting is stable: matches that are still tied after the
+// TieBreak keep the order of the memories file.
+type Ranking struct {
+	CommandWeight     float64
+	DescriptionWeight float64
+	TieBreak          TieBreak
+}
+
+// DefaultRanking weights Command and Description equally and keeps file order
+func DefaultRanking() Ranking {
+	return Ranking{CommandWeight: 1, DescriptionWeight: 1, TieBreak: TieBreakOrder}
+}
+
+// NewRanking returns the ranking configured in the app options
+func NewRanking(opts AppOptions) Ranking {
+	ranking := DefaultRanking()
+	ranking.CommandWeight = opts.CommandWeight
+	ranking.DescriptionWeight = opts.DescriptionWeight
+	if opts.TieBreak != "" {
+		ranking.TieBreak = opts.TieBreak
+	}
+	return ranking
+}
+
+// Score returns the weighted score of a match
+func (r Ranking) Score(match Match) int {
+	weighted := r.CommandWeight*float64(match.CommandScore) + r.DescriptionWeight*float64(match.DescriptionScore)
+	return int(math.Round(weighted))
+}
+
+// Rank sets the weighted score of all matches and sorts them
+func (r Ranking) Rank(matches []Match) []Match {
+	for i := range matches {
+		matches[i].Score = r.Score(matches[i])
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return r.less(matches[i], matches[j])
+	})
+	return matches
+}
+
+// less returns whether `a` is ranked before `b`
+func (r Ranking) less(a Match, b Match) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	switch r.TieBreak {
+	case TieBreakLength:
+		aLen := utf8.RuneCountInString(a.Memory.Command)
+		bLen := utf8.RuneCountInString(b.Memory.Command)
+		if aLen != bLen {
+			return aLen < bLen
+		}
+	case TieBreakUsage:
+		if a.Memory.Usage != b.Memory.Usage {
+			return a.Memory.Usage > b.Memory.Usage
+		}
+	}
+	return a.Index < b.Index
+}
+
+// Merge merges lists of matches that are already ranked into a single
+// ranked list, without sorting again
+func (r Ranking) Merge(lists [][]Match) []Match {
+	if len(lists) == 0 {
+		return []Match{}
+	}
+	for len(lists) > 1 {
+		merged := make([][]Match, 0, (len(lists)+1)/2)
+		for i := 0; i < len(lists); i += 2 {
+			if i+1 == len(lists) {
+				merged = append(merged, lists[i])
+				continue
+			}
+			merged = append(merged, r.merge(lists[i], lists[i+1]))
+		}
+		lists = merged
+	}
+	return lists[0]
+}
+
+// merge merges two ranked lists, keeping `a` first on ties
+func (r Ranking) merge(a []Match, b []Match) []Match {
+	merged := make([]Match, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if r.less(b[0], a[0]) {
+			merged, b = append(merged, b[0]), b[1:]
+		} else {
+			merged, a = append(merged, a[0]), a[1:]
+		}
+	}
+	return append(append(merged, a...), b...)
+}
+
+// Explain returns a description of how the score of a match was calculated
+func (r Ranking) Explain(match Match) string {
+	explanation := fmt.Sprintf(
+		"score %d = cmd %d*%g + desc %d*%g",
+		match.Score,
+		match.CommandScore,
+		r.CommandWeight,
+		match.DescriptionScore,
+		r.DescriptionWeight,
+	)
+	switch r.TieBreak {
+	case TieBreakLength:
+		explanation += fmt.Sprintf(", length %d", utf8.RuneCountInString(match.Memory.Command))
+	case TieBreakUsage:
+		explanation += fmt.Sprintf(", usage %d", match.Memory.Usage)
+	}
+	return explanation + fmt.Sprintf(", #%d", match.Index+1)
+}
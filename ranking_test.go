@@ -0,0 +1,84 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__Ranking(t *testing.T) {
+	memories := []sazed.Memory{
+		{Command: "a long command", Usage: 1},
+		{Command: "short", Usage: 5},
+		{Command: "medium cmd", Usage: 3},
+	}
+	matches := func() []sazed.Match {
+		return []sazed.Match{
+			{Memory: memories[0], Index: 0, CommandScore: 10},
+			{Memory: memories[1], Index: 1, CommandScore: 10},
+			{Memory: memories[2], Index: 2, CommandScore: 4, DescriptionScore: 8},
+		}
+	}
+	indexes := func(matches []sazed.Match) []int {
+		out := []int{}
+		for _, match := range matches {
+			out = append(out, match.Index)
+		}
+		return out
+	}
+
+	t.Run("default ranking sums scores and keeps file order", func(t *testing.T) {
+		ranked := sazed.DefaultRanking().Rank(matches())
+		assert.Equal(t, []int{2, 0, 1}, indexes(ranked))
+		assert.Equal(t, 12, ranked[0].Score)
+	})
+	t.Run("weights", func(t *testing.T) {
+		ranking := sazed.Ranking{CommandWeight: 2, DescriptionWeight: 0.5, TieBreak: sazed.TieBreakOrder}
+		ranked := ranking.Rank(matches())
+		assert.Equal(t, []int{0, 1, 2}, indexes(ranked))
+		assert.Equal(t, []int{20, 20, 12}, []int{ranked[0].Score, ranked[1].Score, ranked[2].Score})
+	})
+	t.Run("tie break by length", func(t *testing.T) {
+		ranking := sazed.Ranking{CommandWeight: 1, DescriptionWeight: 0, TieBreak: sazed.TieBreakLength}
+		assert.Equal(t, []int{1, 0, 2}, indexes(ranking.Rank(matches())))
+	})
+	t.Run("tie break by usage", func(t *testing.T) {
+		ranking := sazed.Ranking{CommandWeight: 1, DescriptionWeight: 1, TieBreak: sazed.TieBreakUsage}
+		ms := matches()
+		ms[2].DescriptionScore = 6
+		assert.Equal(t, []int{1, 2, 0}, indexes(ranking.Rank(ms)))
+	})
+	t.Run("merges ranked lists", func(t *testing.T) {
+		ranking := sazed.DefaultRanking()
+		all := matches()
+		first := ranking.Rank([]sazed.Match{all[0], all[2]})
+		second := ranking.Rank([]sazed.Match{all[1]})
+		assert.Equal(t, []int{2, 0, 1}, indexes(ranking.Merge([][]sazed.Match{first, second, {}})))
+		assert.Equal(t, []sazed.Match{}, ranking.Merge(nil))
+	})
+	t.Run("explain", func(t *testing.T) {
+		ranking := sazed.Ranking{CommandWeight: 2, DescriptionWeight: 0.5, TieBreak: sazed.TieBreakUsage}
+		ranked := ranking.Rank(matches())
+		assert.Equal(t, "score 12 = cmd 4*2 + desc 8*0.5, usage 3, #3", ranking.Explain(ranked[2]))
+	})
+	t.Run("from app options", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.DefaultRanking(), sazed.NewRanking(opts))
+		opts, err = sazed.ParseAppOptions([]string{"--command-weight=2", "--tie-break=usage"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			sazed.Ranking{CommandWeight: 2, DescriptionWeight: 1, TieBreak: sazed.TieBreakUsage},
+			sazed.NewRanking(opts),
+		)
+		opts, err = sazed.ParseAppOptions([]string{"--description-weight=0"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			sazed.Ranking{CommandWeight: 1, DescriptionWeight: 0, TieBreak: sazed.TieBreakOrder},
+			sazed.NewRanking(opts),
+		)
+	})
+}
@@ -0,0 +1,80 @@
+// This file contains memory references: `{{@mem:id}}` in a command is
+// replaced by the command of the memory with that id when memories load.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var referenceRegexp = regexp.MustCompile(`\{\{@mem:([^{}]*)\}\}`)
+
+// ExpandReferences replaces the references in the commands and steps of
+// `memories` by the referenced commands, recursively. Fails on unknown
+// references, reference cycles and duplicate ids.
+func ExpandReferences(memories []Memory) ([]Memory, error) {
+	byID := map[string]Memory{}
+	for _, memory := range memories {
+		if memory.ID == "" {
+			continue
+		}
+		if _, found := byID[memory.ID]; found {
+			return nil, fmt.Errorf("duplicate memory id %q", memory.ID)
+		}
+		byID[memory.ID] = memory
+	}
+	expanded := slices.Clone(memories)
+	for i, memory := range expanded {
+		stack := []string{}
+		if memory.ID != "" {
+			stack = append(stack, memory.ID)
+		}
+		var err error
+		if expanded[i].Command, err = expandReferences(memory.Command, byID, stack); err != nil {
+			return nil, err
+		}
+		expanded[i].Steps = slices.Clone(memory.Steps)
+		for j, step := range memory.Steps {
+			if expanded[i].Steps[j].Command, err = expandReferences(step.Command, byID, stack); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// expandReferences expands the references in `command`. `stack` are the ids
+// of the memories being expanded, to detect cycles.
+func expandReferences(command string, byID map[string]Memory, stack []string) (string, error) {
+	var err error
+	expanded := referenceRegexp.ReplaceAllStringFunc(command, func(reference string) string {
+		if err != nil {
+			return reference
+		}
+		id := strings.TrimSpace(referenceRegexp.FindStringSubmatch(reference)[1])
+		path := append(slices.Clone(stack), id)
+		if slices.Contains(stack, id) {
+			err = fmt.Errorf("memory reference cycle: %s", strings.Join(path, " -> "))
+			return reference
+		}
+		memory, ok := byID[id]
+		if !ok {
+			err = fmt.Errorf("unknown memory reference %q", id)
+			return reference
+		}
+		var s string
+		s, err = expandReferences(referencedCommand(memory), byID, path)
+		return s
+	})
+	return expanded, err
+}
+
+// referencedCommand returns what a reference to `m` expands to
+func referencedCommand(m Memory) string {
+	if m.Command == "" && IsWorkflow(m) {
+		return WorkflowCommand(m.Steps)
+	}
+	return m.Command
+}
@@ -0,0 +1,64 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestExpandReferences(t *testing.T) {
+	t.Run("expands references inline", func(t *testing.T) {
+		memories, err := sazed.ExpandReferences([]sazed.Memory{
+			{ID: "kube-ctx", Command: "kubectl config use-context {{context}}"},
+			{Command: "{{@mem:kube-ctx}} && kubectl apply -f {{file}}"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "kubectl config use-context {{context}} && kubectl apply -f {{file}}", memories[1].Command)
+		assert.Equal(t, "kubectl config use-context {{context}}", memories[0].Command)
+	})
+	t.Run("expands nested references and steps", func(t *testing.T) {
+		memories, err := sazed.ExpandReferences([]sazed.Memory{
+			{ID: "profile", Command: "AWS_PROFILE={{profile}}"},
+			{ID: "aws", Command: "{{@mem:profile}} aws"},
+			{Steps: []sazed.Step{{Command: "{{@mem:aws}} s3 ls"}}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "AWS_PROFILE={{profile}} aws", memories[1].Command)
+		assert.Equal(t, "AWS_PROFILE={{profile}} aws s3 ls", memories[2].Steps[0].Command)
+	})
+	t.Run("reports cycles", func(t *testing.T) {
+		_, err := sazed.ExpandReferences([]sazed.Memory{
+			{ID: "a", Command: "{{@mem:b}}"},
+			{ID: "b", Command: "{{@mem:a}}"},
+		})
+		assert.ErrorContains(t, err, "memory reference cycle: a -> b -> a")
+	})
+	t.Run("reports unknown references", func(t *testing.T) {
+		_, err := sazed.ExpandReferences([]sazed.Memory{{Command: "{{@mem:nope}} ls"}})
+		assert.ErrorContains(t, err, `unknown memory reference "nope"`)
+	})
+	t.Run("reports duplicate ids", func(t *testing.T) {
+		_, err := sazed.ExpandReferences([]sazed.Memory{{ID: "a", Command: "ls"}, {ID: "a", Command: "pwd"}})
+		assert.ErrorContains(t, err, `duplicate memory id "a"`)
+	})
+}
+
+func TestReferencePlaceholders(t *testing.T) {
+	defer cleanup()
+	reader := strings.NewReader(strings.Join([]string{
+		"- {id: kube-ctx, command: \"kubectl config use-context {{context}}\"}",
+		"- {command: \"{{@mem:kube-ctx}} && kubectl apply -f {{file}}\"}",
+	}, "\n"))
+	memories, err := sazed.LoadMemoriesFromYaml(reader)
+	assert.Nil(t, err)
+
+	m := update(newTestModel(), sazed.LoadedMemories(memories[1:]))
+	m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Equal(t, sazed.PageEdit, m.CurrentPage)
+	assert.Len(t, m.EditTextInputs, 2)
+	assert.Equal(t, "context: ", m.EditTextInputs[0].Prompt)
+}
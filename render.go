@@ -0,0 +1,126 @@
+// This file contains the `render` subcommand, which renders a memory
+// without the TUI.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const RenderSubcommand = "render"
+
+// RenderOptions are the options for the `render` subcommand
+type RenderOptions struct {
+	AppOptions
+	ID     string
+	Query  string
+	Values map[string]string
+}
+
+// setFlag is a flag.Value for repeated `--set name=value` flags
+type setFlag map[string]string
+
+func (f setFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for name, value := range f {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f setFlag) Set(s string) error {
+	name, value, found := strings.Cut(s, "=")
+	if !found || name == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	f[name] = value
+	return nil
+}
+
+// ParseRenderOptions parses the options for the `render` subcommand from CLI
+// Arguments and a map of environmental variables
+func ParseRenderOptions(cliArgs []string, envMap map[string]string) (RenderOptions, error) {
+	opts := RenderOptions{Values: map[string]string{}}
+	flagSet := flag.NewFlagSet("sazed render", flag.ContinueOnError)
+	flagSet.StringVar(&opts.ID, "id", "", "ID of the memory to render")
+	flagSet.StringVar(&opts.Query, "query", "", "Render the best match for this query")
+	flagSet.Var(setFlag(opts.Values), "set", "Value for a placeholder, as name=value (repeatable)")
+	appOpts, err := parseAppOptions(flagSet, cliArgs, envMap)
+	if err != nil {
+		return opts, err
+	}
+	opts.AppOptions = appOpts
+	if (opts.ID == "") == (opts.Query == "") {
+		return opts, fmt.Errorf("exactly one of --id or --query is required")
+	}
+	return opts, nil
+}
+
+// FindMemory returns the memory with the given ID or, if no ID is given,
+// the best match for `query` using `searcher`.
+func FindMemory(memories []Memory, id string, query string, searcher IFuzzy) (Memory, error) {
+	if id != "" {
+		for _, memory := range memories {
+			if memory.ID == id {
+				return memory, nil
+			}
+		}
+		return Memory{}, fmt.Errorf("no memory with id %q", id)
+	}
+	matches := searcher.GetMatches(memories, query)
+	if len(matches) == 0 {
+		return Memory{}, fmt.Errorf("no memory matches query %q", query)
+	}
+	return matches[0].Memory, nil
+}
+
+// RenderNamed renders `memory` using the values by placeholder name, joining
+// the steps of workflows with `separator`. Fails listing the placeholders
+// without a value.
+func RenderNamed(memory Memory, values map[string]string, separator JoinSeparator) (string, error) {
+	placeholders, err := EditPlaceholders(memory)
+	if err != nil {
+		return "", fmt.Errorf("invalid placeholder at %w", err)
+	}
+	placeholderValues := make([]string, len(placeholders))
+	missing := []string{}
+	for i, placeholder := range placeholders {
+		value, found := values[placeholder.Name]
+		if !found {
+			if !slices.Contains(missing, placeholder.Name) {
+				missing = append(missing, placeholder.Name)
+			}
+			continue
+		}
+		placeholderValues[i] = value
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for placeholders: %s", strings.Join(missing, ", "))
+	}
+	executions, err := RenderExecutions(memory, placeholderValues)
+	if err != nil {
+		return "", err
+	}
+	return CombineExecutions(executions, separator).ShellCommand(), nil
+}
+
+// RunRender runs the `render` subcommand, writing the rendered command to `sink`
+func RunRender(opts RenderOptions, sink OutputSink) error {
+	memories, err := LoadMemoriesFile(opts.MemoriesFile)
+	if err != nil {
+		return err
+	}
+	memory, err := FindMemory(memories, opts.ID, opts.Query, NewSearcher(opts.SearchMode, NewSearchOptions(opts.AppOptions)))
+	if err != nil {
+		return err
+	}
+	rendered, err := RenderNamed(memory, opts.Values, opts.Separator)
+	if err != nil {
+		return err
+	}
+	return sink.Output(rendered)
+}
@@ -0,0 +1,98 @@
+package main_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__ParseRenderOptions(t *testing.T) {
+	t.Run("parses id and values", func(t *testing.T) {
+		args := []string{"--id", "deploy", "--set", "env=prod", "--set", "tag=v1.2=rc", "--memories-file=/foo"}
+
+		opts, err := sazed.ParseRenderOptions(args, map[string]string{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "deploy", opts.ID)
+		assert.Equal(t, map[string]string{"env": "prod", "tag": "v1.2=rc"}, opts.Values)
+		assert.Equal(t, "/foo", opts.MemoriesFile)
+	})
+	t.Run("requires id or query", func(t *testing.T) {
+		_, err := sazed.ParseRenderOptions([]string{}, map[string]string{})
+		assert.ErrorContains(t, err, "exactly one of --id or --query is required")
+	})
+	t.Run("errors on invalid set", func(t *testing.T) {
+		_, err := sazed.ParseRenderOptions([]string{"--query", "foo", "--set", "foo"}, map[string]string{})
+		assert.ErrorContains(t, err, "expected name=value")
+	})
+}
+
+func Test__FindMemory(t *testing.T) {
+	memories := []sazed.Memory{
+		{ID: "one", Command: "cmd1", Description: "Memory 1"},
+		{ID: "two", Command: "foo", Description: "Bar"},
+	}
+	t.Run("by id", func(t *testing.T) {
+		memory, err := sazed.FindMemory(memories, "two", "", sazed.NewFuzzy())
+		assert.Nil(t, err)
+		assert.Equal(t, memories[1], memory)
+	})
+	t.Run("unknown id", func(t *testing.T) {
+		_, err := sazed.FindMemory(memories, "three", "", sazed.NewFuzzy())
+		assert.EqualError(t, err, `no memory with id "three"`)
+	})
+	t.Run("by query", func(t *testing.T) {
+		memory, err := sazed.FindMemory(memories, "", "bar", sazed.NewFuzzy())
+		assert.Nil(t, err)
+		assert.Equal(t, memories[1], memory)
+	})
+	t.Run("no match for query", func(t *testing.T) {
+		_, err := sazed.FindMemory(memories, "", "zzz", sazed.NewFuzzy())
+		assert.EqualError(t, err, `no memory matches query "zzz"`)
+	})
+}
+
+func Test__RenderNamed(t *testing.T) {
+	t.Run("renders by name", func(t *testing.T) {
+		memory := sazed.Memory{Command: "deploy --env {{env}} --tag {{tag}} # {{env}}"}
+		rendered, err := sazed.RenderNamed(memory, map[string]string{"env": "prod", "tag": "v1"}, sazed.JoinSeparatorAnd)
+		assert.Nil(t, err)
+		assert.Equal(t, "deploy --env prod --tag v1 # prod", rendered)
+	})
+	t.Run("renders cwd and env", func(t *testing.T) {
+		memory := sazed.Memory{Command: "make {{target}}", Cwd: "/repos/{{repo}}", Env: map[string]string{"TARGET": "{{target}}"}}
+		rendered, err := sazed.RenderNamed(memory, map[string]string{"target": "all", "repo": "api"}, sazed.JoinSeparatorAnd)
+		assert.Nil(t, err)
+		assert.Equal(t, "(cd /repos/api && export TARGET=all && make all)", rendered)
+	})
+	t.Run("lists missing placeholders", func(t *testing.T) {
+		memory := sazed.Memory{Command: "deploy {{env}} {{tag}} {{env}} {{region}}"}
+		_, err := sazed.RenderNamed(memory, map[string]string{"tag": "v1"}, sazed.JoinSeparatorAnd)
+		assert.EqualError(t, err, "missing values for placeholders: env, region")
+	})
+	t.Run("errors on invalid placeholder", func(t *testing.T) {
+		_, err := sazed.RenderNamed(sazed.Memory{Command: "deploy {{env"}, map[string]string{}, sazed.JoinSeparatorAnd)
+		assert.EqualError(t, err, "invalid placeholder at column 8: unterminated placeholder")
+	})
+}
+
+func Test__RunRender(t *testing.T) {
+	memoriesFile := path.Join(t.TempDir(), "memories.yaml")
+	content := "- {id: deploy, command: \"deploy {{env}}\", description: Deploys}"
+	_ = os.WriteFile(memoriesFile, []byte(content), 0644)
+	opts := sazed.RenderOptions{
+		AppOptions: sazed.AppOptions{MemoriesFile: memoriesFile},
+		ID:         "deploy",
+		Values:     map[string]string{"env": "prod"},
+	}
+	out := bytes.Buffer{}
+
+	err := sazed.RunRender(opts, sazed.WriterSink{Writer: &out})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "deploy prod", out.String())
+}
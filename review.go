@@ -0,0 +1,152 @@
+// This file contains the review page, where the rendered command can be
+// edited before it's output.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// ReviewTextAreaHeight is how many lines the command uses in the review page
+const ReviewTextAreaHeight = 3
+
+// StartReview shows the review page with the command of `execution` in an
+// editable textarea. Its cwd and env are kept as they are.
+func StartReview(m Model, execution Execution) (Model, tea.Cmd) {
+	m.ReviewExecution = execution
+	m.ReviewTextArea = textarea.New()
+	m.ReviewTextArea.ShowLineNumbers = false
+	m.ReviewTextArea.Prompt = ""
+	m.ReviewTextArea.Cursor.SetMode(cursor.CursorStatic)
+	m.ReviewTextArea.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter"))
+	m.ReviewTextArea.SetHeight(ReviewTextAreaHeight)
+	if m.Width > 0 {
+		m.ReviewTextArea.SetWidth(m.Width)
+	}
+	m.ReviewTextArea.SetValue(execution.Command)
+	m.ReviewSave = false
+	m.ReviewDescriptionInput = textinput.New()
+	m.ReviewDescriptionInput.Cursor.SetMode(cursor.CursorStatic)
+	m.ReviewDescriptionInput.Prompt = "Description: "
+	m.ReviewDescriptionInput.SetValue(m.SelectedMemory.Description)
+	m.CurrentPage = PageReview
+	return m, m.ReviewTextArea.Focus()
+}
+
+// ToggleReviewSave chooses whether the reviewed command is saved as a new
+// memory. The description of the new memory is edited while saving.
+func ToggleReviewSave(m Model) (Model, tea.Cmd) {
+	m.ReviewSave = !m.ReviewSave
+	if m.ReviewSave {
+		m.ReviewTextArea.Blur()
+		return m, m.ReviewDescriptionInput.Focus()
+	}
+	m.ReviewDescriptionInput.Blur()
+	return m, m.ReviewTextArea.Focus()
+}
+
+// ConfirmReview quits with the reviewed execution as output, setting
+// NewMemory if it should be saved
+func ConfirmReview(m Model) (Model, tea.Cmd) {
+	execution := m.ReviewExecution
+	execution.Command = m.ReviewTextArea.Value()
+	if strings.TrimSpace(execution.Command) == "" {
+		return m, nil
+	}
+	if m.ReviewSave {
+		m.NewMemory = Memory{
+			Command:     execution.Command,
+			Description: m.ReviewDescriptionInput.Value(),
+			Cwd:         execution.Cwd,
+		}
+		if len(execution.Env) > 0 {
+			m.NewMemory.Env = map[string]string{}
+			for _, envVar := range execution.Env {
+				m.NewMemory.Env[envVar.Name] = envVar.Value
+			}
+		}
+	}
+	return OutputExecution(m, execution)
+}
+
+// BackFromReview returns to the placeholders
+func BackFromReview(m Model) Model {
+	m.CurrentPage = PageEdit
+	return m
+}
+
+// UpdateReview updates the focused input of the review page
+func UpdateReview(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if m.ReviewSave {
+		m.ReviewDescriptionInput, cmd = m.ReviewDescriptionInput.Update(msg)
+	} else {
+		m.ReviewTextArea, cmd = m.ReviewTextArea.Update(msg)
+	}
+	return m, cmd
+}
+
+func ViewCommandReview(m Model) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString("Review the command:\n")
+	stringBuilder.WriteString(m.ReviewTextArea.View())
+	stringBuilder.WriteString("\n")
+	if m.ReviewExecution.Cwd != "" {
+		stringBuilder.WriteString("Cwd: " + m.ReviewExecution.Cwd + "\n")
+	}
+	for _, envVar := range m.ReviewExecution.Env {
+		stringBuilder.WriteString("Env: " + envVar.Name + "=" + envVar.Value + "\n")
+	}
+	if m.ReviewSave {
+		stringBuilder.WriteString("Saving as a new memory\n")
+		stringBuilder.WriteString(m.ReviewDescriptionInput.View())
+		stringBuilder.WriteString("\n")
+	}
+	return stringBuilder.String()
+}
+
+// savedMemory is how new memories are written to the memories file
+type savedMemory struct {
+	Command     string            `yaml:"command"`
+	Description string            `yaml:"description,omitempty"`
+	Cwd         string            `yaml:"cwd,omitempty"`
+	Env         map[string]string `yaml:"env,omitempty"`
+}
+
+// AppendMemory appends `memory` to the memories file at `path`, keeping the
+// existing content as is
+func AppendMemory(path string, memory Memory) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read memories file: %w", err)
+	}
+	newContent, err := yaml.Marshal([]savedMemory{{
+		Command:     memory.Command,
+		Description: memory.Description,
+		Cwd:         memory.Cwd,
+		Env:         memory.Env,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to serialize memory: %w", err)
+	}
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		newContent = append([]byte("\n"), newContent...)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open memories file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(newContent); err != nil {
+		return fmt.Errorf("failed to write memories file: %w", err)
+	}
+	return nil
+}
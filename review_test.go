@@ -0,0 +1,127 @@
+package main_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestReview(t *testing.T) {
+	review := func(opts *sazed.AppOptions) { opts.Review = true }
+	t.Run("reviews the rendered command", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(review), sazed.LoadedMemories([]sazed.Memory{memory5()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "a")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "b")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageReview, m.CurrentPage)
+		assert.Equal(t, "echo a b end", m.ReviewTextArea.Value())
+		assert.Equal(t, "", sazed.QuitOutput)
+
+		m = typeText(m, " --flag")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, "echo a b end --flag", sazed.QuitOutput)
+		assert.Equal(t, sazed.Memory{}, m.NewMemory)
+	})
+	t.Run("outputs commands without placeholders right away", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(review), sazed.LoadedMemories([]sazed.Memory{memory1()}))
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, sazed.PageSelect, m.CurrentPage)
+		assert.Equal(t, "cmd1", sazed.QuitOutput)
+	})
+	t.Run("goes back to the placeholders", func(t *testing.T) {
+		m := update(newTestModel(review), sazed.LoadedMemories([]sazed.Memory{memory5()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlJ})
+		assert.Equal(t, sazed.PageReview, m.CurrentPage)
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, sazed.PageEdit, m.CurrentPage)
+		assert.Len(t, m.EditTextInputs, 2)
+	})
+	t.Run("saves as a new memory", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(review), sazed.LoadedMemories([]sazed.Memory{memory4()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "a")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+		assert.True(t, m.ReviewSave)
+		assert.Equal(t, "not bar", m.ReviewDescriptionInput.Value())
+		m = typeText(m, " edited")
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Equal(t, "echo a", sazed.QuitOutput)
+		assert.Equal(t, sazed.Memory{Command: "echo a", Description: "not bar edited"}, m.NewMemory)
+	})
+	t.Run("keeps the cwd and env", func(t *testing.T) {
+		defer cleanup()
+		memory := sazed.Memory{Command: "echo {{value}}", Cwd: "/tmp", Env: map[string]string{"A": "1"}}
+		m := update(newTestModel(review), sazed.LoadedMemories([]sazed.Memory{memory}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "a")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, "echo a", m.ReviewTextArea.Value())
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+		expected := sazed.Execution{Command: "echo a", Cwd: "/tmp", Env: []sazed.EnvVar{{Name: "A", Value: "1"}}}
+		assert.Equal(t, expected, sazed.QuitExecution)
+		assert.Equal(t, sazed.Memory{Command: "echo a", Cwd: "/tmp", Env: map[string]string{"A": "1"}}, m.NewMemory)
+	})
+	t.Run("does not output an empty command", func(t *testing.T) {
+		m := update(newTestModel(review), sazed.LoadedMemories([]sazed.Memory{memory4()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m.ReviewTextArea.SetValue(" ")
+
+		_, cmd := sazed.ConfirmReview(m)
+
+		assert.Nil(t, cmd)
+	})
+}
+
+func TestAppendMemory(t *testing.T) {
+	t.Run("appends to the memories file", func(t *testing.T) {
+		memoriesFile := path.Join(t.TempDir(), "memories.yaml")
+		_ = os.WriteFile(memoriesFile, []byte("# my memories\n- {command: foo, description: bar}"), 0644)
+
+		err := sazed.AppendMemory(memoriesFile, sazed.Memory{Command: "ls -la", Description: "list"})
+
+		assert.Nil(t, err)
+		content, _ := os.ReadFile(memoriesFile)
+		assert.Equal(t, "# my memories\n- {command: foo, description: bar}\n- command: ls -la\n  description: list\n", string(content))
+		memories, err := sazed.LoadMemoriesFile(memoriesFile)
+		assert.Nil(t, err)
+		assert.Len(t, memories, 2)
+	})
+	t.Run("writes the cwd and env", func(t *testing.T) {
+		memoriesFile := path.Join(t.TempDir(), "memories.yaml")
+		_ = os.WriteFile(memoriesFile, []byte(""), 0644)
+
+		err := sazed.AppendMemory(memoriesFile, sazed.Memory{Command: "ls", Cwd: "/tmp", Env: map[string]string{"A": "1"}})
+
+		assert.Nil(t, err)
+		memories, err := sazed.LoadMemoriesFile(memoriesFile)
+		assert.Nil(t, err)
+		assert.Equal(t, "/tmp", memories[0].Cwd)
+		assert.Equal(t, map[string]string{"A": "1"}, memories[0].Env)
+	})
+	t.Run("errors on missing file", func(t *testing.T) {
+		err := sazed.AppendMemory(path.Join(t.TempDir(), "memories.yaml"), sazed.Memory{Command: "ls"})
+		assert.ErrorContains(t, err, "failed to read memories file")
+	})
+}
@@ -0,0 +1,293 @@
+// This file contains the search modes, which are the available IFuzzy
+// implementations.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchMode is how user input is matched against memories
+type SearchMode string
+
+const SearchModeFuzzy SearchMode = "fuzzy"
+const SearchModeSubstring SearchMode = "substring"
+const SearchModePrefix SearchMode = "prefix"
+const SearchModeRegex SearchMode = "regex"
+const SearchModeTypo SearchMode = "typo"
+
+// SearchModes are all search modes, in the order they are cycled
+var SearchModes = []SearchMode{SearchModeFuzzy, SearchModeSubstring, SearchModePrefix, SearchModeRegex, SearchModeTypo}
+
+// ParseSearchMode returns the SearchMode named `s`
+func ParseSearchMode(s string) (SearchMode, error) {
+	for _, mode := range SearchModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown search mode %q", s)
+}
+
+// NextSearchMode returns the search mode after `mode`
+func NextSearchMode(mode SearchMode) SearchMode {
+	for i, m := range SearchModes {
+		if m == mode {
+			return SearchModes[(i+1)%len(SearchModes)]
+		}
+	}
+	return SearchModeFuzzy
+}
+
+// SearchOptions are the options shared by all search modes
+type SearchOptions struct {
+	Ranking  Ranking
+	Synonyms Synonyms
+	// SmartCase makes searches case sensitive if the input has uppercase letters
+	SmartCase bool
+	// FoldAccents ignores accents and other diacritics (see FoldAccents)
+	FoldAccents bool
+	// InContextOnly skips memories whose `when` conditions don't hold
+	InContextOnly bool
+}
+
+// DefaultSearchOptions returns the search options used if nothing is configured
+func DefaultSearchOptions() SearchOptions {
+	return SearchOptions{Ranking: DefaultRanking(), Synonyms: Synonyms{}, SmartCase: true, FoldAccents: true, InContextOnly: true}
+}
+
+// NewSearchOptions returns the search options configured in the app options
+func NewSearchOptions(opts AppOptions) SearchOptions {
+	return SearchOptions{
+		Ranking:     NewRanking(opts),
+		Synonyms:    ParseSynonyms(opts.Config.Synonyms),
+		SmartCase:   opts.SmartCase,
+		FoldAccents: opts.FoldAccents,
+		// Toggled in the TUI (see ToggleShowAll)
+		InContextOnly: true,
+	}
+}
+
+// NewSearcher returns the IFuzzy implementation for `mode`
+func NewSearcher(mode SearchMode, opts SearchOptions) IFuzzy {
+	var searcher IFuzzy
+	switch mode {
+	case SearchModeSubstring:
+		searcher = SubstringSearch{Ranking: opts.Ranking, SmartCase: opts.SmartCase}
+	case SearchModePrefix:
+		searcher = PrefixSearch{Ranking: opts.Ranking, SmartCase: opts.SmartCase}
+	case SearchModeRegex:
+		searcher = RegexSearch{Ranking: opts.Ranking, SmartCase: opts.SmartCase}
+	case SearchModeTypo:
+		searcher = TypoSearch{Ranking: opts.Ranking, SmartCase: opts.SmartCase}
+	default:
+		searcher = Fuzzy{Ranking: opts.Ranking, Synonyms: opts.Synonyms, SmartCase: opts.SmartCase}
+	}
+	if opts.FoldAccents {
+		searcher = FoldAccentsSearch{Inner: searcher}
+	}
+	if opts.InContextOnly {
+		searcher = InContextSearch{Inner: searcher}
+	}
+	return searcher
+}
+
+// fieldMatcher matches the user input against a single field (Command or
+// Description) of a memory.
+type fieldMatcher func(field string) (score int, matchedIndexes []int, ok bool)
+
+// matchMemories returns the memories matching by Command or Description,
+// without ranking.
+func matchMemories(memories []Memory, matcher fieldMatcher) []Match {
+	matches := []Match{}
+	for i, memory := range memories {
+		match := Match{Memory: memory, Index: i}
+		commandScore, commandIndexes, commandOk := matcher(memory.Command)
+		descriptionScore, descriptionIndexes, descriptionOk := matcher(memory.Description)
+		if !commandOk && !descriptionOk {
+			continue
+		}
+		if commandOk {
+			match.CommandScore = commandScore
+			match.CommandMatchedIndexes = commandIndexes
+		}
+		if descriptionOk {
+			match.DescriptionScore = descriptionScore
+			match.DescriptionMatchedIndexes = descriptionIndexes
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// allMatches returns all memories as matches, for when the input is empty
+func allMatches(memories []Memory) []Match {
+	var matches []Match
+	for i, memory := range memories {
+		matches = append(matches, Match{Memory: memory, Index: i})
+	}
+	return matches
+}
+
+// rangeIndexes returns the indexes from `beg` (inclusive) to `end` (exclusive)
+func rangeIndexes(beg, end int) []int {
+	indexes := make([]int, 0, end-beg)
+	for i := beg; i < end; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// positionScore scores a match of length `length` at `position`. Longer and
+// earlier matches score higher.
+func positionScore(position, length int) int {
+	return max(10*length-position, 1)
+}
+
+// SubstringSearch matches memories containing the input (case insensitive,
+// unless SmartCase and the input has uppercase letters)
+type SubstringSearch struct {
+	Ranking   Ranking
+	SmartCase bool
+}
+
+func NewSubstringSearch() SubstringSearch {
+	return SubstringSearch{Ranking: DefaultRanking()}
+}
+
+// GetMatches implements IFuzzy
+func (s SubstringSearch) GetMatches(memories []Memory, input string) []Match {
+	if input == "" {
+		return s.Ranking.Rank(allMatches(memories))
+	}
+	caseSensitive := IsCaseSensitive(input, s.SmartCase)
+	return s.Ranking.Rank(matchMemories(memories, func(field string) (int, []int, bool) {
+		for index := range field {
+			if length, ok := hasPrefixMatch(field[index:], input, caseSensitive); ok {
+				return positionScore(index, length), rangeIndexes(index, index+length), true
+			}
+		}
+		return 0, nil, false
+	}))
+}
+
+// PrefixSearch matches memories with a word starting with the input (case
+// insensitive, unless SmartCase and the input has uppercase letters)
+type PrefixSearch struct {
+	Ranking   Ranking
+	SmartCase bool
+}
+
+func NewPrefixSearch() PrefixSearch {
+	return PrefixSearch{Ranking: DefaultRanking()}
+}
+
+// GetMatches implements IFuzzy
+func (s PrefixSearch) GetMatches(memories []Memory, input string) []Match {
+	if input == "" {
+		return s.Ranking.Rank(allMatches(memories))
+	}
+	caseSensitive := IsCaseSensitive(input, s.SmartCase)
+	return s.Ranking.Rank(matchMemories(memories, func(field string) (int, []int, bool) {
+		for index := range field {
+			if !isWordStart(field, index) {
+				continue
+			}
+			if length, ok := hasPrefixMatch(field[index:], input, caseSensitive); ok {
+				return positionScore(index, length), rangeIndexes(index, index+length), true
+			}
+		}
+		return 0, nil, false
+	}))
+}
+
+// RegexSearch matches memories using the input as a regular expression. An
+// invalid expression (e.g. while typing it) matches nothing. With SmartCase,
+// expressions without uppercase letters are case insensitive.
+type RegexSearch struct {
+	Ranking   Ranking
+	SmartCase bool
+}
+
+func NewRegexSearch() RegexSearch {
+	return RegexSearch{Ranking: DefaultRanking()}
+}
+
+// GetMatches implements IFuzzy
+func (s RegexSearch) GetMatches(memories []Memory, input string) []Match {
+	if input == "" {
+		return s.Ranking.Rank(allMatches(memories))
+	}
+	expression := input
+	if !IsCaseSensitive(input, s.SmartCase) {
+		expression = "(?i)" + input
+	}
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return []Match{}
+	}
+	return s.Ranking.Rank(matchMemories(memories, func(field string) (int, []int, bool) {
+		location := regex.FindStringIndex(field)
+		if location == nil {
+			return 0, nil, false
+		}
+		return positionScore(location[0], location[1]-location[0]), rangeIndexes(location[0], location[1]), true
+	}))
+}
+
+// isWordStart returns true if the rune at byte `index` of `s` starts a word
+func isWordStart(s string, index int) bool {
+	if index == 0 {
+		return true
+	}
+	previous, _ := utf8.DecodeLastRuneInString(s[:index])
+	return !unicode.IsLetter(previous) && !unicode.IsDigit(previous)
+}
+
+// isWordEnd returns true if the word before byte `index` of `s` ends there
+func isWordEnd(s string, index int) bool {
+	if index == len(s) {
+		return true
+	}
+	next, _ := utf8.DecodeRuneInString(s[index:])
+	return !unicode.IsLetter(next) && !unicode.IsDigit(next)
+}
+
+// hasPrefixMatch returns true if `s` starts with `prefix`, comparing with
+// case folding unless `caseSensitive`, together with the byte length of the
+// prefix in `s`.
+func hasPrefixMatch(s string, prefix string, caseSensitive bool) (length int, ok bool) {
+	if caseSensitive {
+		return len(prefix), strings.HasPrefix(s, prefix)
+	}
+	return hasPrefixFold(s, prefix)
+}
+
+// hasPrefixFold returns true if `s` starts with `prefix` under Unicode case
+// folding, together with the byte length of the prefix in `s`.
+func hasPrefixFold(s string, prefix string) (length int, ok bool) {
+	for _, p := range prefix {
+		r, size := utf8.DecodeRuneInString(s[length:])
+		if size == 0 || !equalFoldRune(r, p) {
+			return 0, false
+		}
+		length += size
+	}
+	return length, true
+}
+
+// equalFoldRune returns true if `a` and `b` are equal under Unicode case folding
+func equalFoldRune(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
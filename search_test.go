@@ -0,0 +1,101 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func searchMemories() []sazed.Memory {
+	return []sazed.Memory{
+		{Command: "docker system prune", Description: "Removes unused data"},
+		{Command: "docker compose down", Description: "Stops the Compose stack"},
+		{Command: "ls -lha", Description: "Lists files"},
+	}
+}
+
+func Test__SearchModes(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		mode, err := sazed.ParseSearchMode("substring")
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.SearchModeSubstring, mode)
+		_, err = sazed.ParseSearchMode("foo")
+		assert.ErrorContains(t, err, "unknown search mode")
+	})
+	t.Run("cycle", func(t *testing.T) {
+		assert.Equal(t, sazed.SearchModeSubstring, sazed.NextSearchMode(sazed.SearchModeFuzzy))
+		assert.Equal(t, sazed.SearchModeTypo, sazed.NextSearchMode(sazed.SearchModeRegex))
+		assert.Equal(t, sazed.SearchModeFuzzy, sazed.NextSearchMode(sazed.SearchModeTypo))
+	})
+	t.Run("new searcher", func(t *testing.T) {
+		assert.Equal(t, sazed.NewFuzzy(), sazed.NewSearcher(sazed.SearchModeFuzzy, sazed.SearchOptions{Ranking: sazed.DefaultRanking(), Synonyms: sazed.Synonyms{}}))
+		assert.Equal(t, sazed.NewRegexSearch(), sazed.NewSearcher(sazed.SearchModeRegex, sazed.SearchOptions{Ranking: sazed.DefaultRanking(), Synonyms: sazed.Synonyms{}}))
+	})
+}
+
+func Test__SubstringSearch(t *testing.T) {
+	memories := searchMemories()
+	t.Run("matches exact substring case insensitive", func(t *testing.T) {
+		matches := sazed.NewSubstringSearch().GetMatches(memories, "COMPOSE")
+		assert.Equal(t, []sazed.Match{
+			{
+				Memory:                    memories[1],
+				Index:                     1,
+				Score:                     123,
+				CommandScore:              63,
+				DescriptionScore:          60,
+				CommandMatchedIndexes:     []int{7, 8, 9, 10, 11, 12, 13},
+				DescriptionMatchedIndexes: []int{10, 11, 12, 13, 14, 15, 16},
+			},
+		}, matches)
+	})
+	t.Run("does not match fuzzy", func(t *testing.T) {
+		assert.Empty(t, sazed.NewSubstringSearch().GetMatches(memories, "dkr"))
+	})
+	t.Run("non-ASCII", func(t *testing.T) {
+		memories := []sazed.Memory{{Command: "echo", Description: "Configuração"}}
+		matches := sazed.NewSubstringSearch().GetMatches(memories, "AÇÃO")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, []int{8, 9, 10, 11, 12, 13}, matches[0].DescriptionMatchedIndexes)
+	})
+	t.Run("empty input returns all", func(t *testing.T) {
+		assert.Len(t, sazed.NewSubstringSearch().GetMatches(memories, ""), 3)
+	})
+}
+
+func Test__PrefixSearch(t *testing.T) {
+	memories := searchMemories()
+	t.Run("matches start of words", func(t *testing.T) {
+		matches := sazed.NewPrefixSearch().GetMatches(memories, "pru")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[0], matches[0].Memory)
+		assert.Equal(t, []int{14, 15, 16}, matches[0].CommandMatchedIndexes)
+	})
+	t.Run("does not match middle of words", func(t *testing.T) {
+		assert.Empty(t, sazed.NewPrefixSearch().GetMatches(memories, "une"))
+	})
+	t.Run("sorts by score", func(t *testing.T) {
+		matches := sazed.NewPrefixSearch().GetMatches(memories, "l")
+		assert.Equal(t, memories[2], matches[0].Memory)
+	})
+}
+
+func Test__RegexSearch(t *testing.T) {
+	memories := searchMemories()
+	t.Run("matches regex", func(t *testing.T) {
+		matches := sazed.NewRegexSearch().GetMatches(memories, "^docker (system|image)")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[0], matches[0].Memory)
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, matches[0].CommandMatchedIndexes)
+	})
+	t.Run("invalid regex matches nothing", func(t *testing.T) {
+		assert.Empty(t, sazed.NewRegexSearch().GetMatches(memories, "docker ("))
+	})
+	t.Run("ignores case", func(t *testing.T) {
+		assert.Len(t, sazed.NewRegexSearch().GetMatches(memories, "^DOCKER system"), 1)
+		smartCase := sazed.RegexSearch{Ranking: sazed.DefaultRanking(), SmartCase: true}
+		assert.Len(t, smartCase.GetMatches(memories, "^docker system"), 1)
+		assert.Empty(t, smartCase.GetMatches(memories, "^DOCKER system"))
+	})
+}
@@ -0,0 +1,37 @@
+package main
+
+import "strings"
+
+// Synonyms maps a (lower cased) word to the words that mean the same
+type Synonyms map[string][]string
+
+// ParseSynonyms parses groups of synonyms separated by `~`, like
+// "delete ~ rm ~ remove". Every word of a group is a synonym of the others.
+func ParseSynonyms(groups []string) Synonyms {
+	synonyms := Synonyms{}
+	for _, group := range groups {
+		words := []string{}
+		for _, word := range strings.Split(group, "~") {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word != "" {
+				words = append(words, word)
+			}
+		}
+		for _, word := range words {
+			for _, synonym := range words {
+				if synonym != word {
+					synonyms[word] = append(synonyms[word], synonym)
+				}
+			}
+		}
+	}
+	return synonyms
+}
+
+// Expand sets the synonyms of each query term
+func (s Synonyms) Expand(terms []QueryTerm) []QueryTerm {
+	for i := range terms {
+		terms[i].Synonyms = s[strings.ToLower(terms[i].Text)]
+	}
+	return terms
+}
@@ -0,0 +1,73 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__ParseSynonyms(t *testing.T) {
+	synonyms := sazed.ParseSynonyms([]string{"delete ~ rm ~ Remove", "list ~ ls", "alone", " ~ "})
+	assert.Equal(t, sazed.Synonyms{
+		"delete": {"rm", "remove"},
+		"rm":     {"delete", "remove"},
+		"remove": {"delete", "rm"},
+		"list":   {"ls"},
+		"ls":     {"list"},
+	}, synonyms)
+}
+
+func Test__SynonymsExpand(t *testing.T) {
+	synonyms := sazed.ParseSynonyms([]string{"list ~ ls"})
+	terms := synonyms.Expand(sazed.ParseQuery("List files"))
+	assert.Equal(t, []sazed.QueryTerm{
+		{Text: "List", Synonyms: []string{"ls"}},
+		{Text: "files"},
+	}, terms)
+}
+
+func Test__FuzzySynonymsAndKeywords(t *testing.T) {
+	memories := []sazed.Memory{
+		{Command: "docker ps -a -q | xargs docker rm", Description: "Stop and rm docker containers"},
+		{Command: "kubectl get pods", Description: "Show pods", Keywords: []string{"containers", "k8s"}},
+		{Command: "ls -lha", Description: "Show files"},
+	}
+	fuzzy := sazed.NewFuzzy()
+	fuzzy.Synonyms = sazed.ParseSynonyms([]string{"delete ~ rm ~ remove"})
+
+	t.Run("matches synonyms", func(t *testing.T) {
+		matches := fuzzy.GetMatches(memories, "'delete 'containers")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[0], matches[0].Memory)
+		assert.Equal(t, []int{9, 10, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28}, matches[0].DescriptionMatchedIndexes)
+	})
+	t.Run("matches synonyms in plain queries", func(t *testing.T) {
+		matches := fuzzy.GetMatches(memories, "delete containers")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[0], matches[0].Memory)
+	})
+	t.Run("matches synonyms as whole words", func(t *testing.T) {
+		fuzzy := sazed.NewFuzzy()
+		fuzzy.Synonyms = sazed.ParseSynonyms([]string{"list ~ ls"})
+		memories := []sazed.Memory{{Command: "false", Description: "Fails"}, {Command: "ls -lha"}}
+		matches := fuzzy.GetMatches(memories, "list")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[1], matches[0].Memory)
+		assert.Equal(t, []int{0, 1}, matches[0].CommandMatchedIndexes)
+	})
+	t.Run("negated synonyms", func(t *testing.T) {
+		matches := fuzzy.GetMatches(memories, "!delete")
+		assert.Len(t, matches, 2)
+	})
+	t.Run("matches keywords", func(t *testing.T) {
+		matches := fuzzy.GetMatches(memories, "'k8s")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[1], matches[0].Memory)
+		assert.Equal(t, 30, matches[0].DescriptionScore)
+		assert.Empty(t, matches[0].DescriptionMatchedIndexes)
+	})
+	t.Run("keywords are not matched for cmd terms", func(t *testing.T) {
+		assert.Empty(t, fuzzy.GetMatches(memories, "cmd:'k8s"))
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import "strings"
+
+// Template is a command compiled into literal segments and placeholders, so
+// it can be counted and rendered many times without being parsed again.
+type Template struct {
+	Segments     []Segment
+	Placeholders []Placeholder
+	// Err is the parse error of the command, if any
+	Err error
+}
+
+// Segment is either a literal piece of a command or a reference to one of
+// the Template placeholders.
+type Segment struct {
+	Literal string
+	// Placeholder is the index in Template.Placeholders, or -1 for literals
+	Placeholder int
+}
+
+// CompileTemplate parses `s` into a Template
+func CompileTemplate(s string) Template {
+	placeholders, err := ParsePlaceholders(s)
+	if err != nil {
+		return Template{Segments: []Segment{}, Placeholders: placeholders, Err: err}
+	}
+	segments := make([]Segment, 0, 2*len(placeholders)+1)
+	last := 0
+	for i, placeholder := range placeholders {
+		if placeholder.Beg > last {
+			segments = append(segments, Segment{Literal: s[last:placeholder.Beg], Placeholder: -1})
+		}
+		segments = append(segments, Segment{Placeholder: i})
+		last = placeholder.End + 1
+	}
+	if last < len(s) {
+		segments = append(segments, Segment{Literal: s[last:], Placeholder: -1})
+	}
+	return Template{Segments: segments, Placeholders: placeholders}
+}
+
+// CountPlaceholders returns how many placeholders the template has
+func (t Template) CountPlaceholders() int {
+	return len(t.Placeholders)
+}
+
+// Render replaces the `i`th placeholder with the `i`th value in
+// `placeholderValues`. Missing values are rendered as empty strings.
+func (t Template) Render(placeholderValues []string) (string, error) {
+	if t.Err != nil {
+		return "", t.Err
+	}
+	stringBuilder := strings.Builder{}
+	for _, segment := range t.Segments {
+		if segment.Placeholder == -1 {
+			stringBuilder.WriteString(segment.Literal)
+			continue
+		}
+		if segment.Placeholder < len(placeholderValues) {
+			stringBuilder.WriteString(placeholderValues[segment.Placeholder])
+		}
+	}
+	return stringBuilder.String(), nil
+}
@@ -0,0 +1,51 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__CompileTemplate(t *testing.T) {
+	t.Run("literal only", func(t *testing.T) {
+		template := sazed.CompileTemplate("echo foo")
+		assert.Nil(t, template.Err)
+		assert.Equal(t, []sazed.Segment{{Literal: "echo foo", Placeholder: -1}}, template.Segments)
+		assert.Equal(t, 0, template.CountPlaceholders())
+	})
+	t.Run("literals and placeholders", func(t *testing.T) {
+		template := sazed.CompileTemplate("{{foo}} bar {{baz}}{{boz}}")
+		assert.Nil(t, template.Err)
+		assert.Equal(t, []sazed.Segment{
+			{Placeholder: 0},
+			{Literal: " bar ", Placeholder: -1},
+			{Placeholder: 1},
+			{Placeholder: 2},
+		}, template.Segments)
+		assert.Equal(t, 3, template.CountPlaceholders())
+		assert.Equal(t, "boz", template.Placeholders[2].Name)
+	})
+	t.Run("invalid placeholder", func(t *testing.T) {
+		template := sazed.CompileTemplate("echo {{foo}} {{bar")
+		assert.ErrorContains(t, template.Err, "unterminated placeholder")
+		assert.Equal(t, 1, template.CountPlaceholders())
+	})
+}
+
+func Test__TemplateRender(t *testing.T) {
+	t.Run("renders values", func(t *testing.T) {
+		rendered, err := sazed.CompileTemplate("echo {{foo}} {{bar}} end").Render([]string{"1", "2"})
+		assert.Nil(t, err)
+		assert.Equal(t, "echo 1 2 end", rendered)
+	})
+	t.Run("values are not parsed as placeholders", func(t *testing.T) {
+		rendered, err := sazed.CompileTemplate("echo {{foo}} {{bar}}").Render([]string{"{{bar}}", "2"})
+		assert.Nil(t, err)
+		assert.Equal(t, "echo {{bar}} 2", rendered)
+	})
+	t.Run("errors on invalid placeholder", func(t *testing.T) {
+		_, err := sazed.CompileTemplate("echo {{").Render([]string{})
+		assert.ErrorContains(t, err, "unterminated placeholder")
+	})
+}
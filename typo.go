@@ -0,0 +1,175 @@
+// This file contains the typo tolerant search, which matches words by edit
+// distance instead of subsequences.
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TypoSearch matches memories where every word of the input is close (by
+// edit distance, counting transpositions) to a word or word prefix in the
+// Command or Description.
+type TypoSearch struct {
+	Ranking   Ranking
+	SmartCase bool
+}
+
+func NewTypoSearch() TypoSearch {
+	return TypoSearch{Ranking: DefaultRanking()}
+}
+
+// word is a word of a string with it's byte offsets
+type word struct {
+	Text string
+	Beg  int
+	End  int
+}
+
+// GetMatches implements IFuzzy
+func (s TypoSearch) GetMatches(memories []Memory, input string) []Match {
+	caseSensitive := IsCaseSensitive(input, s.SmartCase)
+	if !caseSensitive {
+		input = strings.ToLower(input)
+	}
+	queryWords := splitWords(input)
+	if len(queryWords) == 0 {
+		return s.Ranking.Rank(allMatches(memories))
+	}
+	matches := []Match{}
+	for i, memory := range memories {
+		match := Match{Memory: memory, Index: i}
+		commandWords := splitWords(memory.Command)
+		descriptionWords := splitWords(memory.Description)
+		matchedAll := true
+		for _, queryWord := range queryWords {
+			commandScore, commandIndexes, commandOk := matchTypoWord(queryWord.Text, commandWords, caseSensitive)
+			descriptionScore, descriptionIndexes, descriptionOk := matchTypoWord(queryWord.Text, descriptionWords, caseSensitive)
+			if !commandOk && !descriptionOk {
+				matchedAll = false
+				break
+			}
+			if commandOk {
+				match.CommandScore += commandScore
+				match.CommandMatchedIndexes = mergeIndexes(match.CommandMatchedIndexes, commandIndexes)
+			}
+			if descriptionOk {
+				match.DescriptionScore += descriptionScore
+				match.DescriptionMatchedIndexes = mergeIndexes(match.DescriptionMatchedIndexes, descriptionIndexes)
+			}
+		}
+		if matchedAll {
+			matches = append(matches, match)
+		}
+	}
+	return s.Ranking.Rank(matches)
+}
+
+// MaxTypos returns how many edits are tolerated for a query word of
+// `length` runes. Short words must match exactly, or everything would.
+func MaxTypos(length int) int {
+	switch {
+	case length <= 2:
+		return 0
+	case length <= 5:
+		return 1
+	}
+	return 2
+}
+
+// matchTypoWord finds the field word closest to `queryWord` (lower cased,
+// unless `caseSensitive`), comparing with both the whole word and it's prefix
+// of the same length.
+func matchTypoWord(queryWord string, fieldWords []word, caseSensitive bool) (score int, matchedIndexes []int, ok bool) {
+	query := []rune(queryWord)
+	maxTypos := MaxTypos(len(query))
+	bestDistance := maxTypos + 1
+	var best word
+	for _, fieldWord := range fieldWords {
+		// Lowered rune by rune, so the runes of `candidate` are the runes of
+		// fieldWord.Text and the prefix end below is found in the same text
+		candidate := []rune(fieldWord.Text)
+		if !caseSensitive {
+			for i, r := range candidate {
+				candidate[i] = unicode.ToLower(r)
+			}
+		}
+		distance := EditDistance(query, candidate)
+		end := fieldWord.End
+		if len(candidate) > len(query) {
+			prefixDistance := EditDistance(query, candidate[:len(query)])
+			if prefixDistance < distance {
+				distance = prefixDistance
+				end = fieldWord.Beg + runesByteLength(fieldWord.Text, len(query))
+			}
+		}
+		if distance < bestDistance {
+			bestDistance = distance
+			best = word{Text: fieldWord.Text, Beg: fieldWord.Beg, End: end}
+		}
+	}
+	if bestDistance > maxTypos {
+		return 0, nil, false
+	}
+	score = 10*len(query) - 15*bestDistance
+	return max(score, 1), rangeIndexes(best.Beg, best.End), true
+}
+
+// EditDistance returns the optimal string alignment distance between `a`
+// and `b`: the Levenshtein distance where swapping two adjacent runes counts
+// as a single edit.
+func EditDistance(a []rune, b []rune) int {
+	// d[i][j] is the distance between a[:i] and b[:j]
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// splitWords splits `s` in words of letters and digits
+func splitWords(s string) []word {
+	words := []word{}
+	beg := -1
+	for i, r := range s {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && beg == -1 {
+			beg = i
+		}
+		if !isWordRune && beg != -1 {
+			words = append(words, word{Text: s[beg:i], Beg: beg, End: i})
+			beg = -1
+		}
+	}
+	if beg != -1 {
+		words = append(words, word{Text: s[beg:], Beg: beg, End: len(s)})
+	}
+	return words
+}
+
+// runesByteLength returns the byte length of the first `n` runes of `s`
+func runesByteLength(s string, n int) int {
+	length := 0
+	for i := 0; i < n && length < len(s); i++ {
+		_, size := utf8.DecodeRuneInString(s[length:])
+		length += size
+	}
+	return length
+}
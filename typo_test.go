@@ -0,0 +1,67 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__EditDistance(t *testing.T) {
+	td := []struct {
+		a, b     string
+		expected int
+	}{
+		{"docker", "docker", 0},
+		{"dokcer", "docker", 1},
+		{"prnue", "prune", 1},
+		{"dcoker", "docker", 1},
+		{"doker", "docker", 1},
+		{"dockerr", "docker", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"ação", "acao", 2},
+	}
+	for _, tc := range td {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sazed.EditDistance([]rune(tc.a), []rune(tc.b)))
+		})
+	}
+}
+
+func Test__TypoSearch(t *testing.T) {
+	memories := searchMemories()
+	t.Run("tolerates typos and transpositions", func(t *testing.T) {
+		matches := sazed.NewTypoSearch().GetMatches(memories, "dokcer prnue")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[0], matches[0].Memory)
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 14, 15, 16, 17, 18}, matches[0].CommandMatchedIndexes)
+	})
+	t.Run("matches word prefixes", func(t *testing.T) {
+		matches := sazed.NewTypoSearch().GetMatches(memories, "cmopose")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, memories[1], matches[0].Memory)
+		assert.Equal(t, []int{7, 8, 9, 10, 11, 12, 13}, matches[0].CommandMatchedIndexes)
+		assert.Equal(t, []int{10, 11, 12, 13, 14, 15, 16}, matches[0].DescriptionMatchedIndexes)
+	})
+	t.Run("matches prefixes with multi-byte runes", func(t *testing.T) {
+		memories := []sazed.Memory{{Command: "ÉCRIRE ÀBCDEFGH"}}
+		matches := sazed.NewTypoSearch().GetMatches(memories, "àbcd")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, []int{8, 9, 10, 11, 12}, matches[0].CommandMatchedIndexes)
+	})
+	t.Run("exact matches rank higher", func(t *testing.T) {
+		matches := sazed.NewTypoSearch().GetMatches(memories, "docker")
+		assert.Len(t, matches, 2)
+		assert.Equal(t, 60, matches[0].CommandScore)
+		matches = sazed.NewTypoSearch().GetMatches(memories, "dokcer")
+		assert.Equal(t, 45, matches[0].CommandScore)
+	})
+	t.Run("short words must be exact", func(t *testing.T) {
+		assert.Empty(t, sazed.NewTypoSearch().GetMatches(memories, "sl"))
+		assert.Len(t, sazed.NewTypoSearch().GetMatches(memories, "ls"), 1)
+	})
+	t.Run("empty input returns all", func(t *testing.T) {
+		assert.Len(t, sazed.NewTypoSearch().GetMatches(memories, " "), 3)
+	})
+}
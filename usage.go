@@ -0,0 +1,83 @@
+// This file contains the usage store, which counts how many times each
+// memory was selected.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Usage maps a memory (see UsageKey) to how many times it was selected
+type Usage map[string]int
+
+// UsageKey identifies a memory in the usage store
+func UsageKey(m Memory) string {
+	if m.ID != "" {
+		return "id:" + m.ID
+	}
+	return "command:" + m.Command
+}
+
+// LoadUsage reads the usage store at `path`. A missing file is an empty store.
+func LoadUsage(path string) (Usage, error) {
+	usage := Usage{}
+	if path == "" {
+		return usage, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return usage, nil
+	}
+	if err != nil {
+		return usage, fmt.Errorf("failed to read usage file: %w", err)
+	}
+	if err := yaml.Unmarshal(content, &usage); err != nil {
+		return usage, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+	if usage == nil {
+		usage = Usage{}
+	}
+	return usage, nil
+}
+
+// SetUsage sets the Usage of each memory from the store
+func SetUsage(memories []Memory, usage Usage) []Memory {
+	for i := range memories {
+		memories[i].Usage = usage[UsageKey(memories[i])]
+	}
+	return memories
+}
+
+// RecordsUsage returns whether usage is recorded, which is only needed by the
+// usage tie break
+func RecordsUsage(opts AppOptions) bool {
+	return opts.TieBreak == TieBreakUsage
+}
+
+// RecordUsage increments the usage count of a memory in the store at `usagePath`
+func RecordUsage(usagePath string, m Memory) error {
+	if usagePath == "" {
+		return nil
+	}
+	usage, err := LoadUsage(usagePath)
+	if err != nil {
+		return err
+	}
+	usage[UsageKey(m)]++
+	content, err := yaml.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("failed to serialize usage: %w", err)
+	}
+	if err := os.MkdirAll(path.Dir(usagePath), 0755); err != nil {
+		return fmt.Errorf("failed to create usage dir: %w", err)
+	}
+	if err := os.WriteFile(usagePath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	return nil
+}
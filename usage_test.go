@@ -0,0 +1,39 @@
+package main_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func Test__Usage(t *testing.T) {
+	t.Run("missing file is empty", func(t *testing.T) {
+		usage, err := sazed.LoadUsage(path.Join(t.TempDir(), "usage.yaml"))
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.Usage{}, usage)
+	})
+	t.Run("records and loads usage", func(t *testing.T) {
+		usageFile := path.Join(t.TempDir(), "state", "usage.yaml")
+		memories := []sazed.Memory{{ID: "foo", Command: "echo foo"}, {Command: "echo bar"}}
+
+		assert.Nil(t, sazed.RecordUsage(usageFile, memories[0]))
+		assert.Nil(t, sazed.RecordUsage(usageFile, memories[1]))
+		assert.Nil(t, sazed.RecordUsage(usageFile, memories[1]))
+
+		usage, err := sazed.LoadUsage(usageFile)
+		assert.Nil(t, err)
+		assert.Equal(t, sazed.Usage{"id:foo": 1, "command:echo bar": 2}, usage)
+		memories = sazed.SetUsage(memories, usage)
+		assert.Equal(t, []int{1, 2}, []int{memories[0].Usage, memories[1].Usage})
+	})
+	t.Run("only recorded for the usage tie break", func(t *testing.T) {
+		opts, err := sazed.ParseAppOptions([]string{}, map[string]string{})
+		assert.Nil(t, err)
+		assert.False(t, sazed.RecordsUsage(opts))
+		opts, err = sazed.ParseAppOptions([]string{"--tie-break=usage"}, map[string]string{})
+		assert.Nil(t, err)
+		assert.True(t, sazed.RecordsUsage(opts))
+	})
+}
@@ -2,41 +2,169 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
+// MatchHighlightStyle is the style for characters matching the user input
+var MatchHighlightStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+
 func ViewCommandSelection(m Model) string {
-	body := "Please select a command\n"
+	body := fmt.Sprintf("Please select a command (mode: %s, %s to change)\n", m.SearchMode, m.Keys.CycleSearchMode.Help().Key)
 	body += m.SearchTextInput.View() + "\n"
+	marked := ""
+	if len(m.MarkedMemories) > 0 {
+		marked = fmt.Sprintf(" (%d marked)", len(m.MarkedMemories))
+	}
+	if !m.SearchOptions.InContextOnly {
+		marked += " (showing all)"
+	}
+	body += fmt.Sprintf("%d/%d%s ----------------------\n", min(m.MatchCursor+1, len(m.Matches)), len(m.Matches), marked)
+
+	match, hasMatch := CursorMatch(m)
+	if !m.ShowPreview || !hasMatch {
+		return body + ViewMatches(m, m.Width)
+	}
+
+	// Shows the preview of the match under the cursor at the side if the
+	// terminal is wide enough, otherwise at the bottom
+	if PreviewOnSide(m) {
+		matchesWidth := m.Width / 2
+		previewWidth := m.Width - matchesWidth - len(PreviewSeparator)
+		matches := strings.TrimSuffix(ViewMatches(m, matchesWidth), "\n")
+		preview := ViewPreview(match.Memory, previewWidth, max(m.Height-SelectionHeaderLines-SelectionFooterLines, 1))
+		return body + joinPreviewOnSide(matches, preview) + "\n"
+	}
+	previewHeight := -1
+	if m.Height > 0 {
+		previewHeight = PreviewBottomLines(m) - 1
+	}
+	body += ViewMatches(m, m.Width)
 	body += "----------------------\n"
+	body += ViewPreview(match.Memory, max(m.Width, 0)-1, previewHeight) + "\n"
+	return body
+}
+
+// ViewMatches renders the visible matches, fitting them in `width` columns if
+// it's known (greater than zero)
+func ViewMatches(m Model, width int) string {
+	body := ""
 
-	for i, match := range m.Matches {
+	// Fit commands and descriptions in the terminal width, if known
+	printLength := m.AppOpts.CommandPrintLength
+	descriptionLength := -1
+	if width > 0 {
+		printLength = min(printLength, max(width-3, 1))
+		descriptionLength = max(width-7, 1)
+	}
+
+	// Only the visible matches are printed (see ScrollToCursor)
+	beg := max(min(m.MatchOffset, len(m.Matches)), 0)
+	end := min(beg+VisibleMatchesCount(m), len(m.Matches))
+	for i := beg; i < end; i++ {
+		match := m.Matches[i]
 		cursor := " "
-		if i == m.MatchCursor {
+		switch {
+		case i == m.MatchCursor && IsMarked(m, match):
+			cursor = ">*"
+		case i == m.MatchCursor:
 			cursor = ">>"
+		case IsMarked(m, match):
+			cursor = "*"
 		}
-		printLength := fmt.Sprintf("%d", m.AppOpts.CommandPrintLength)
 
 		// Prints command on first line
-		format := "%-2s %-" + printLength + "." + printLength + "s\n"
-		body += fmt.Sprintf(format, cursor, match.Memory.Command)
+		command := PadRight(Truncate(match.Memory.Command, printLength), printLength)
+		body += fmt.Sprintf("%-2s %s\n", cursor, Highlight(command, match.CommandMatchedIndexes, MatchHighlightStyle))
 
-		// Prints description on second line
-		body += fmt.Sprintf("      |%s\n", match.Memory.Description)
+		// Prints description on second line, with the scores so they are
+		// truncated together
+		description := match.Memory.Description
+		if m.AppOpts.ExplainScores {
+			description += " [" + m.SearchOptions.Ranking.Explain(match) + "]"
+		}
+		if descriptionLength != -1 {
+			description = Truncate(description, descriptionLength)
+		}
+		description = Highlight(description, match.DescriptionMatchedIndexes, MatchHighlightStyle)
+		body += fmt.Sprintf("      |%s\n", description)
 	}
 
 	return body
 }
 
+// ViewHelp renders the help footer with the keybindings of the current page
+func ViewHelp(m Model) string {
+	m.Help.Width = m.Width
+	return m.Help.ShortHelpView(PageKeyBindings(m.Keys, m.CurrentPage))
+}
+
+// Highlight renders the runes of `s` starting at the byte `indexes` with
+// `style`. Indexes past the end of `s` (e.g. after truncating) are ignored.
+func Highlight(s string, indexes []int, style lipgloss.Style) string {
+	if len(indexes) == 0 {
+		return s
+	}
+	stringBuilder := strings.Builder{}
+	runBeg := -1 // byte offset where the current highlighted run begins
+	for i := range s {
+		highlighted := slices.Contains(indexes, i)
+		if highlighted && runBeg == -1 {
+			runBeg = i
+		}
+		if !highlighted && runBeg != -1 {
+			stringBuilder.WriteString(style.Render(s[runBeg:i]))
+			runBeg = -1
+		}
+		if !highlighted {
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			stringBuilder.WriteRune(r)
+		}
+	}
+	if runBeg != -1 {
+		stringBuilder.WriteString(style.Render(s[runBeg:]))
+	}
+	return stringBuilder.String()
+}
+
+// Truncate returns the first `length` runes of `s`
+func Truncate(s string, length int) string {
+	runes := 0
+	for i := range s {
+		if runes == length {
+			return s[:i]
+		}
+		runes++
+	}
+	return s
+}
+
+// PadRight pads `s` with spaces up to `length` runes
+func PadRight(s string, length int) string {
+	return s + strings.Repeat(" ", max(length-utf8.RuneCountInString(s), 0))
+}
+
 func ViewCommandEdit(m Model) string {
 	// Displays the command with the placeholders replaced by the values
 	originalCmd := m.SelectedMemory.Command
 	placeholderValues := m.GetPlaceholderValues()
-	renderedCmd := Render(originalCmd, placeholderValues)
+	executions, err := RenderExecutions(m.SelectedMemory, placeholderValues)
 	stringBuilder := strings.Builder{}
 	stringBuilder.WriteString("Command: ")
-	stringBuilder.WriteString(renderedCmd)
-	stringBuilder.WriteString("\n")
+	if err != nil {
+		// Show the original command and a warning instead of a half-rendered one
+		stringBuilder.WriteString(originalCmd)
+		stringBuilder.WriteString("\n")
+		stringBuilder.WriteString("Warning: invalid placeholder at ")
+		stringBuilder.WriteString(err.Error())
+		stringBuilder.WriteString("\n")
+	} else {
+		stringBuilder.WriteString(CombineExecutions(executions, m.AppOpts.Separator).ShellCommand())
+		stringBuilder.WriteString("\n")
+	}
 
 	// Allow user to input values for each placeholder
 	for _, input := range m.EditTextInputs {
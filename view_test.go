@@ -4,27 +4,29 @@ import (
 	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/stretchr/testify/assert"
 	sazed "github.com/vitorqb/sazed"
 )
 
 func TestViewCommandEdit(t *testing.T) {
 	t.Run("Renders command on the first line", func(t *testing.T) {
-		model := sazed.InitialModel(sazed.AppOptions{})
+		model := newTestModel()
 		model.SelectedMemory = sazed.Memory{Command: "foo {{bar}} baz"}
 		view := sazed.ViewCommandEdit(model)
 		lines := strings.Split(view, "\n")
 		assert.Equal(t, "Command: foo  baz", lines[0])
 	})
 	t.Run("Renders command on the first line (multiple placeholders)", func(t *testing.T) {
-		model := sazed.InitialModel(sazed.AppOptions{})
+		model := newTestModel()
 		model.SelectedMemory = memory5()
 		view := sazed.ViewCommandEdit(model)
 		lines := strings.Split(view, "\n")
 		assert.Equal(t, "Command: echo   end", lines[0])
 	})
 	t.Run("Replaces placeholders for user input", func(t *testing.T) {
-		model := sazed.InitialModel(sazed.AppOptions{})
+		model := newTestModel()
 		model.SelectedMemory = memory5()
 		model = sazed.SetupEditTextInputs(model)
 		for i, value := range []string{"--opt1", "--opt2"} {
@@ -36,7 +38,7 @@ func TestViewCommandEdit(t *testing.T) {
 	})
 	t.Run("Renders input for each placeholder", func(t *testing.T) {
 		memory := sazed.Memory{Command: "foo {{bar}} baz {{boz}}"}
-		model := sazed.InitialModel(sazed.AppOptions{})
+		model := newTestModel()
 		model.SelectedMemory = memory
 		model = sazed.SetupEditTextInputs(model)
 		model.EditTextInputs[0].SetValue("--opt1")
@@ -48,4 +50,79 @@ func TestViewCommandEdit(t *testing.T) {
 		assert.Equal(t, "bar: --opt1 ", lines[1])
 		assert.Equal(t, "boz: --opt2 ", lines[2])
 	})
+	t.Run("Renders a warning for invalid placeholders", func(t *testing.T) {
+		model := newTestModel()
+		model.SelectedMemory = sazed.Memory{Command: "foo {{bar}} {{baz"}
+		model = sazed.SetupEditTextInputs(model)
+		view := sazed.ViewCommandEdit(model)
+		lines := strings.Split(view, "\n")
+		assert.Equal(t, "Command: foo {{bar}} {{baz", lines[0])
+		assert.Equal(t, "Warning: invalid placeholder at column 13: unterminated placeholder", lines[1])
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	upper := lipgloss.NewStyle().Transform(strings.ToUpper)
+	t.Run("no indexes", func(t *testing.T) {
+		assert.Equal(t, "foo bar", sazed.Highlight("foo bar", nil, upper))
+	})
+	t.Run("highlights indexes", func(t *testing.T) {
+		assert.Equal(t, "FOo BaR", sazed.Highlight("foo bar", []int{0, 1, 4, 6}, upper))
+	})
+	t.Run("non-ASCII", func(t *testing.T) {
+		assert.Equal(t, "aÇÃo", sazed.Highlight("ação", []int{1, 3}, upper))
+	})
+	t.Run("ignores indexes out of range", func(t *testing.T) {
+		assert.Equal(t, "fOo", sazed.Highlight("foo", []int{1, 5, 6}, upper))
+	})
+}
+
+func TestTruncateAndPadRight(t *testing.T) {
+	assert.Equal(t, "açã", sazed.Truncate("ação", 3))
+	assert.Equal(t, "ação", sazed.Truncate("ação", 10))
+	assert.Equal(t, "ação  ", sazed.PadRight("ação", 6))
+	assert.Equal(t, "ação", sazed.PadRight("ação", 2))
+}
+
+func TestViewCommandSelectionHighlight(t *testing.T) {
+	defaultStyle := sazed.MatchHighlightStyle
+	t.Cleanup(func() { sazed.MatchHighlightStyle = defaultStyle })
+	sazed.MatchHighlightStyle = lipgloss.NewStyle().Transform(strings.ToUpper)
+
+	model := newTestModel(func(opts *sazed.AppOptions) { opts.CommandPrintLength = 8 })
+	model.Matches = []sazed.Match{{
+		Memory:                    sazed.Memory{Command: "docker system prune", Description: "Removes unused data"},
+		CommandMatchedIndexes:     []int{0, 1, 7, 14, 15},
+		DescriptionMatchedIndexes: []int{8, 9},
+	}}
+
+	lines := strings.Split(sazed.ViewCommandSelection(model), "\n")
+
+	assert.Equal(t, ">> DOcker S", lines[3])
+	assert.Equal(t, "      |Removes UNused data", lines[4])
+}
+
+func TestViewCommandSelectionViewport(t *testing.T) {
+	model := newTestModel(func(opts *sazed.AppOptions) { opts.CommandPrintLength = 75 })
+	model.Matches = manyMatches(340)
+	model = update(model, tea.WindowSizeMsg{Width: 20, Height: 14})
+	for i := 0; i < 11; i++ {
+		model = update(model, tea.KeyMsg{Type: tea.KeyDown})
+	}
+
+	lines := strings.Split(sazed.ViewCommandSelection(model), "\n")
+
+	assert.Equal(t, "12/340 ----------------------", lines[2])
+	assert.Len(t, lines, 3+5*2+1)
+	assert.Equal(t, "   cmd7             ", lines[3])
+	assert.Equal(t, ">> cmd11            ", lines[11])
+}
+
+func TestViewHelp(t *testing.T) {
+	model := newTestModel()
+	assert.Contains(t, sazed.ViewHelp(model), "ctrl+t")
+	assert.Contains(t, sazed.ViewHelp(model), "ctrl+c")
+	model.CurrentPage = sazed.PageEdit
+	assert.NotContains(t, sazed.ViewHelp(model), "ctrl+t")
+	assert.Contains(t, sazed.ViewHelp(model), "enter")
 }
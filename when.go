@@ -0,0 +1,115 @@
+// This file contains the `when` conditions of memories, which hide memories
+// that make no sense in the current context.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/caarlos0/env/v11"
+)
+
+// When are the conditions for a memory to be shown. All set conditions must
+// hold.
+type When struct {
+	// Cwd is a glob matching the current directory or one of its parents
+	Cwd string
+	// Exists is a file that must exist, relative to the current directory
+	Exists string
+	// Git requires the current directory to be inside a git repository
+	Git bool
+	// Env is an environment variable that must be set
+	Env string
+	// Hostname is a glob matching the hostname
+	Hostname string
+}
+
+// WhenContext is the context `When` conditions are checked against
+type WhenContext struct {
+	Cwd      string
+	Hostname string
+	Env      map[string]string
+}
+
+// NewWhenContext returns the context of the running process
+func NewWhenContext() WhenContext {
+	cwd, _ := os.Getwd()
+	hostname, _ := os.Hostname()
+	return WhenContext{Cwd: cwd, Hostname: hostname, Env: env.ToMap(os.Environ())}
+}
+
+// Holds returns whether all conditions of `w` hold in `ctx`
+func (w When) Holds(ctx WhenContext) bool {
+	if w.Cwd != "" && !slices.ContainsFunc(parentDirs(ctx.Cwd), globMatcher(ExpandHome(w.Cwd))) {
+		return false
+	}
+	if w.Exists != "" && !fileExists(filepath.Join(ctx.Cwd, w.Exists)) {
+		return false
+	}
+	if w.Git && !insideGitRepo(ctx.Cwd) {
+		return false
+	}
+	if _, ok := ctx.Env[w.Env]; w.Env != "" && !ok {
+		return false
+	}
+	if w.Hostname != "" && !globMatcher(w.Hostname)(ctx.Hostname) {
+		return false
+	}
+	return true
+}
+
+// ApplyWhen marks the memories whose conditions don't hold in `ctx` as
+// OutOfContext
+func ApplyWhen(memories []Memory, ctx WhenContext) []Memory {
+	memories = slices.Clone(memories)
+	for i, memory := range memories {
+		memories[i].OutOfContext = memory.When != nil && !memory.When.Holds(ctx)
+	}
+	return memories
+}
+
+// InContextSearch wraps a search mode, skipping the memories that are
+// OutOfContext
+type InContextSearch struct {
+	Inner IFuzzy
+}
+
+// GetMatches implements IFuzzy
+func (s InContextSearch) GetMatches(memories []Memory, input string) []Match {
+	matches := s.Inner.GetMatches(memories, input)
+	return slices.DeleteFunc(matches, func(match Match) bool { return match.Memory.OutOfContext })
+}
+
+// ToggleShowAll shows or hides the memories that are out of context
+func ToggleShowAll(m Model) Model {
+	m.SearchOptions.InContextOnly = !m.SearchOptions.InContextOnly
+	return ResetSearcher(m)
+}
+
+// parentDirs returns `dir` and all its parents
+func parentDirs(dir string) []string {
+	dirs := []string{dir}
+	for parent := filepath.Dir(dir); parent != dir; dir, parent = parent, filepath.Dir(parent) {
+		dirs = append(dirs, parent)
+	}
+	return dirs
+}
+
+func globMatcher(pattern string) func(string) bool {
+	return func(s string) bool {
+		ok, _ := filepath.Match(pattern, s)
+		return ok
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func insideGitRepo(dir string) bool {
+	return slices.ContainsFunc(parentDirs(dir), func(dir string) bool {
+		return fileExists(filepath.Join(dir, ".git"))
+	})
+}
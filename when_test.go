@@ -0,0 +1,81 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func TestWhenHolds(t *testing.T) {
+	repo := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(repo, ".git"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "Makefile"), []byte{}, 0o644))
+	subdir := filepath.Join(repo, "cmd")
+	assert.Nil(t, os.Mkdir(subdir, 0o755))
+	ctx := sazed.WhenContext{Cwd: repo, Hostname: "build-01", Env: map[string]string{"AWS_PROFILE": "dev"}}
+
+	t.Run("no conditions", func(t *testing.T) {
+		assert.True(t, sazed.When{}.Holds(ctx))
+	})
+	t.Run("cwd glob", func(t *testing.T) {
+		assert.True(t, sazed.When{Cwd: filepath.Dir(repo) + "/*"}.Holds(ctx))
+		assert.True(t, sazed.When{Cwd: repo}.Holds(sazed.WhenContext{Cwd: subdir}))
+		assert.False(t, sazed.When{Cwd: "/nope/*"}.Holds(ctx))
+	})
+	t.Run("file exists", func(t *testing.T) {
+		assert.True(t, sazed.When{Exists: "Makefile"}.Holds(ctx))
+		assert.False(t, sazed.When{Exists: "docker-compose.yml"}.Holds(ctx))
+	})
+	t.Run("inside git repo", func(t *testing.T) {
+		assert.True(t, sazed.When{Git: true}.Holds(sazed.WhenContext{Cwd: subdir}))
+		assert.False(t, sazed.When{Git: true}.Holds(sazed.WhenContext{Cwd: t.TempDir()}))
+	})
+	t.Run("env var set", func(t *testing.T) {
+		assert.True(t, sazed.When{Env: "AWS_PROFILE"}.Holds(ctx))
+		assert.False(t, sazed.When{Env: "KUBECONFIG"}.Holds(ctx))
+	})
+	t.Run("hostname", func(t *testing.T) {
+		assert.True(t, sazed.When{Hostname: "build-*"}.Holds(ctx))
+		assert.False(t, sazed.When{Hostname: "laptop"}.Holds(ctx))
+	})
+	t.Run("all conditions must hold", func(t *testing.T) {
+		assert.False(t, sazed.When{Exists: "Makefile", Hostname: "laptop"}.Holds(ctx))
+	})
+}
+
+func TestWhenSearch(t *testing.T) {
+	ctx := sazed.WhenContext{Hostname: "laptop"}
+	memories := sazed.ApplyWhen([]sazed.Memory{
+		{Command: "make build", When: &sazed.When{Hostname: "build-*"}},
+		{Command: "make test"},
+	}, ctx)
+	assert.True(t, memories[0].OutOfContext)
+	assert.False(t, memories[1].OutOfContext)
+
+	t.Run("skips memories out of context", func(t *testing.T) {
+		matches := sazed.NewSearcher(sazed.SearchModeFuzzy, sazed.DefaultSearchOptions()).GetMatches(memories, "make")
+		assert.Len(t, matches, 1)
+		assert.Equal(t, 1, matches[0].Index)
+	})
+	t.Run("toggles showing all", func(t *testing.T) {
+		m := update(newTestModel(), sazed.LoadedMemories(memories))
+		assert.Len(t, m.Matches, 1)
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlL})
+		assert.Len(t, m.Matches, 2)
+		assert.Contains(t, sazed.ViewCommandSelection(m), "(showing all)")
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlL})
+		assert.Len(t, m.Matches, 1)
+	})
+	t.Run("loads conditions from yaml", func(t *testing.T) {
+		loaded, err := sazed.LoadMemoriesFromYaml(strings.NewReader("- {command: make, when: {exists: Makefile, git: true}}\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, &sazed.When{Exists: "Makefile", Git: true}, loaded[0].When)
+	})
+}
@@ -0,0 +1,211 @@
+// This file contains workflows: memories with several steps, that are output
+// as a combined script or run one step at a time.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/caarlos0/env/v11"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Step is one command of a workflow
+type Step struct {
+	Command     string
+	Description string
+}
+
+// StepStatus is the result of running a step
+type StepStatus string
+
+const (
+	StepPending StepStatus = ""
+	StepDone    StepStatus = "done"
+	StepFailed  StepStatus = "failed"
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepFinished is sent when a step finished running
+type StepFinished struct {
+	Index int
+	Err   error
+}
+
+// IsWorkflow returns whether `m` has steps
+func IsWorkflow(m Memory) bool {
+	return len(m.Steps) > 0
+}
+
+// WorkflowCommand returns the commands of all steps, used to search and show
+// a workflow
+func WorkflowCommand(steps []Step) string {
+	commands := make([]string, len(steps))
+	for i, step := range steps {
+		commands[i] = step.Command
+	}
+	return strings.Join(commands, " && ")
+}
+
+// stepMemory returns the memory running a single step of the workflow `m`
+func stepMemory(m Memory, step Step) Memory {
+	return Memory{Command: step.Command, Description: step.Description, Cwd: m.Cwd, Env: m.Env}
+}
+
+// EditPlaceholders returns the placeholders to edit for `m`. Each placeholder
+// of a memory is edited, while workflows share placeholders by name across
+// all steps.
+func EditPlaceholders(m Memory) ([]Placeholder, error) {
+	placeholders, err := MemoryPlaceholders(m)
+	if !IsWorkflow(m) {
+		return placeholders, err
+	}
+	unique := []Placeholder{}
+	seen := map[string]bool{}
+	for _, placeholder := range placeholders {
+		if !seen[placeholder.Name] {
+			seen[placeholder.Name] = true
+			unique = append(unique, placeholder)
+		}
+	}
+	return unique, err
+}
+
+// RenderExecutions renders `m` with the values of EditPlaceholders, returning
+// one execution per step of workflows or a single one otherwise
+func RenderExecutions(m Memory, placeholderValues []string) ([]Execution, error) {
+	if !IsWorkflow(m) {
+		execution, err := RenderExecution(m, placeholderValues)
+		return []Execution{execution}, err
+	}
+	placeholders, err := EditPlaceholders(m)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for i, placeholder := range placeholders {
+		if i < len(placeholderValues) {
+			values[placeholder.Name] = placeholderValues[i]
+		}
+	}
+	executions := make([]Execution, len(m.Steps))
+	for i, step := range m.Steps {
+		memory := stepMemory(m, step)
+		stepPlaceholders, _ := MemoryPlaceholders(memory)
+		stepValues := make([]string, len(stepPlaceholders))
+		for j, placeholder := range stepPlaceholders {
+			stepValues[j] = values[placeholder.Name]
+		}
+		if executions[i], err = RenderExecution(memory, stepValues); err != nil {
+			return nil, err
+		}
+	}
+	return executions, nil
+}
+
+// CombineExecutions returns an execution running all `executions`, joined by
+// `separator`
+func CombineExecutions(executions []Execution, separator JoinSeparator) Execution {
+	if len(executions) == 1 {
+		return executions[0]
+	}
+	commands := make([]string, len(executions))
+	for i, execution := range executions {
+		commands[i] = execution.ShellCommand()
+	}
+	return Execution{Command: separator.Join(commands)}
+}
+
+// StartSteps shows the steps page, to run the `executions` one at a time
+func StartSteps(m Model, executions []Execution) (Model, tea.Cmd) {
+	m.Steps = executions
+	m.StepStatuses = make([]StepStatus, len(executions))
+	m.StepCursor = 0
+	m.CurrentPage = PageSteps
+	return m, nil
+}
+
+// RunCurrentStep runs the step under the cursor, or quits if all steps ran
+func RunCurrentStep(m Model) (Model, tea.Cmd) {
+	if m.StepCursor >= len(m.Steps) {
+		return m, QuitSteps(m)
+	}
+	return m, m.RunStep(m.StepCursor, m.Steps[m.StepCursor])
+}
+
+// QuitSteps quits the steps page, with an error if a step failed so the exit
+// code is not zero
+func QuitSteps(m Model) tea.Cmd {
+	for i, status := range m.StepStatuses {
+		if status == StepFailed {
+			return func() tea.Msg { return QuitWithErr(fmt.Errorf("step %d failed", i+1)) }
+		}
+	}
+	return tea.Quit
+}
+
+// SkipCurrentStep moves to the next step without running the current one
+func SkipCurrentStep(m Model) Model {
+	if m.StepCursor < len(m.Steps) {
+		m.StepStatuses[m.StepCursor] = StepSkipped
+		m.StepCursor++
+	}
+	return m
+}
+
+// PreviousStep moves back to the previous step, e.g. to run it again
+func PreviousStep(m Model) Model {
+	m.StepCursor = max(m.StepCursor-1, 0)
+	return m
+}
+
+// FinishStep records the result of a step, moving to the next one if it
+// succeeded
+func FinishStep(m Model, msg StepFinished) Model {
+	if msg.Index < 0 || msg.Index >= len(m.Steps) {
+		return m
+	}
+	m.StepStatuses = append([]StepStatus{}, m.StepStatuses...)
+	if msg.Err != nil {
+		m.StepStatuses[msg.Index] = StepFailed
+		return m
+	}
+	m.StepStatuses[msg.Index] = StepDone
+	if msg.Index == m.StepCursor {
+		m.StepCursor++
+	}
+	return m
+}
+
+// RunStepInShell runs a step in the user shell, suspending the TUI while it
+// runs
+func RunStepInShell(index int, execution Execution) tea.Cmd {
+	cmd := ShellCmd(ShellFromEnv(env.ToMap(os.Environ())), execution)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return StepFinished{Index: index, Err: err}
+	})
+}
+
+func ViewSteps(m Model) string {
+	stringBuilder := strings.Builder{}
+	stringBuilder.WriteString(fmt.Sprintf("Workflow: %s\n", m.SelectedMemory.Description))
+	for i, execution := range m.Steps {
+		cursor := " "
+		if i == m.StepCursor {
+			cursor = ">>"
+		}
+		status := ""
+		if m.StepStatuses[i] != StepPending {
+			status = " [" + string(m.StepStatuses[i]) + "]"
+		}
+		stringBuilder.WriteString(fmt.Sprintf("%-2s %d. %s%s\n", cursor, i+1, execution.ShellCommand(), status))
+		if i < len(m.SelectedMemory.Steps) && m.SelectedMemory.Steps[i].Description != "" {
+			stringBuilder.WriteString(fmt.Sprintf("      |%s\n", m.SelectedMemory.Steps[i].Description))
+		}
+	}
+	if m.StepCursor >= len(m.Steps) {
+		stringBuilder.WriteString("All steps finished, press enter to quit\n")
+	}
+	return stringBuilder.String()
+}
@@ -0,0 +1,144 @@
+package main_test
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	sazed "github.com/vitorqb/sazed"
+)
+
+func workflowMemory() sazed.Memory {
+	return sazed.CompileMemory(sazed.Memory{
+		Description: "Deploy",
+		Steps: []sazed.Step{
+			{Command: "make build TAG={{tag}}", Description: "Build"},
+			{Command: "make push TAG={{tag}} ENV={{env}}"},
+		},
+	})
+}
+
+func TestEditPlaceholders(t *testing.T) {
+	t.Run("shares placeholders by name across steps", func(t *testing.T) {
+		placeholders, err := sazed.EditPlaceholders(workflowMemory())
+		assert.Nil(t, err)
+		assert.Len(t, placeholders, 2)
+		assert.Equal(t, "tag", placeholders[0].Name)
+		assert.Equal(t, "env", placeholders[1].Name)
+	})
+	t.Run("keeps every placeholder of memories", func(t *testing.T) {
+		placeholders, err := sazed.EditPlaceholders(sazed.CompileMemory(sazed.Memory{Command: "echo {{a}} {{a}}"}))
+		assert.Nil(t, err)
+		assert.Len(t, placeholders, 2)
+	})
+}
+
+func TestRenderExecutions(t *testing.T) {
+	t.Run("renders each step", func(t *testing.T) {
+		executions, err := sazed.RenderExecutions(workflowMemory(), []string{"v1", "prod"})
+		assert.Nil(t, err)
+		assert.Equal(t, []sazed.Execution{
+			{Command: "make build TAG=v1"},
+			{Command: "make push TAG=v1 ENV=prod"},
+		}, executions)
+	})
+	t.Run("keeps the cwd and env in every step", func(t *testing.T) {
+		memory := workflowMemory()
+		memory.Cwd = "/srv/{{env}}"
+		executions, err := sazed.RenderExecutions(memory, []string{"v1", "prod"})
+		assert.Nil(t, err)
+		assert.Equal(t, "/srv/prod", executions[0].Cwd)
+		assert.Equal(t, "/srv/prod", executions[1].Cwd)
+	})
+	t.Run("renders memories as a single execution", func(t *testing.T) {
+		executions, err := sazed.RenderExecutions(memory4(), []string{"foo"})
+		assert.Nil(t, err)
+		assert.Equal(t, []sazed.Execution{{Command: "echo foo"}}, executions)
+	})
+}
+
+func TestCombineExecutions(t *testing.T) {
+	executions := []sazed.Execution{{Command: "a"}, {Command: "b", Cwd: "/tmp"}}
+	assert.Equal(t, "a && (cd /tmp && b)", sazed.CombineExecutions(executions, sazed.JoinSeparatorAnd).Command)
+	assert.Equal(t, "a\n(cd /tmp && b)", sazed.CombineExecutions(executions, sazed.JoinSeparatorNewline).Command)
+	assert.Equal(t, executions[1], sazed.CombineExecutions(executions[1:], sazed.JoinSeparatorAnd))
+}
+
+func TestWorkflow(t *testing.T) {
+	step := func(opts *sazed.AppOptions) { opts.Step = true }
+	t.Run("outputs the combined script", func(t *testing.T) {
+		defer cleanup()
+		m := update(newTestModel(), sazed.LoadedMemories([]sazed.Memory{workflowMemory()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Len(t, m.EditTextInputs, 2)
+		m = typeText(m, "v1")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = typeText(m, "prod")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, "make build TAG=v1 && make push TAG=v1 ENV=prod", sazed.QuitOutput)
+	})
+	t.Run("runs steps one at a time", func(t *testing.T) {
+		defer cleanup()
+		ran := []string{}
+		failing := true
+		m := update(newTestModel(step), sazed.LoadedMemories([]sazed.Memory{workflowMemory()}))
+		m.RunStep = func(index int, execution sazed.Execution) tea.Cmd {
+			ran = append(ran, execution.Command)
+			return func() tea.Msg {
+				if index == 1 && failing {
+					return sazed.StepFinished{Index: index, Err: errors.New("exit status 1")}
+				}
+				return sazed.StepFinished{Index: index}
+			}
+		}
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlJ})
+		assert.Equal(t, sazed.PageSteps, m.CurrentPage)
+		assert.Contains(t, sazed.ViewSteps(m), ">> 1. make build TAG=")
+		assert.Contains(t, sazed.ViewSteps(m), "|Build")
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, []sazed.StepStatus{sazed.StepDone, sazed.StepPending}, m.StepStatuses)
+		assert.Equal(t, 1, m.StepCursor)
+
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.StepFailed, m.StepStatuses[1])
+		assert.Equal(t, 1, m.StepCursor)
+		assert.Contains(t, sazed.ViewSteps(m), "[failed]")
+		quit := update(m, tea.KeyMsg{Type: tea.KeyCtrlC})
+		assert.ErrorContains(t, sazed.QuitErr, "step 2 failed")
+		assert.Equal(t, sazed.StepFailed, quit.StepStatuses[1])
+		sazed.QuitErr = nil
+
+		failing = false
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, 2, m.StepCursor)
+		assert.Contains(t, sazed.ViewSteps(m), "All steps finished")
+		assert.Len(t, ran, 3)
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, sazed.QuitErr)
+		assert.Equal(t, "", sazed.QuitOutput)
+	})
+	t.Run("skips and goes back to steps", func(t *testing.T) {
+		m := update(newTestModel(step), sazed.LoadedMemories([]sazed.Memory{workflowMemory()}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		m = update(m, tea.KeyMsg{Type: tea.KeyCtrlJ})
+		m = update(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, sazed.StepSkipped, m.StepStatuses[0])
+		assert.Equal(t, 1, m.StepCursor)
+		m = update(m, tea.KeyMsg{Type: tea.KeyShiftTab})
+		assert.Equal(t, 0, m.StepCursor)
+	})
+	t.Run("confirms dangerous steps before running", func(t *testing.T) {
+		m := update(newTestModel(step), sazed.LoadedMemories([]sazed.Memory{sazed.CompileMemory(sazed.Memory{
+			Steps: []sazed.Step{{Command: "make clean"}, {Command: "rm -rf build"}},
+		})}))
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageConfirm, m.CurrentPage)
+		m = typeText(m, "yes")
+		m = update(m, tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, sazed.PageSteps, m.CurrentPage)
+		assert.Len(t, m.Steps, 2)
+	})
+}